@@ -0,0 +1,47 @@
+// Command gen writes a random puzzle input for one day to stdout or a file.
+//
+// Usage:
+//
+//	go run ./cmd/gen -day 6 -size 500 -seed 42 > big-input.txt
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"advent-of-code-2024/internal/gen"
+)
+
+func main() {
+	var day = flag.Int("day", 0, fmt.Sprintf("Day to generate input for %v", gen.Days()))
+	var size = flag.Int("size", 100, "Input size (lines, grid side or page count, depending on the day)")
+	var seed = flag.Int64("seed", 1, "Random seed; the same seed always produces the same input")
+	var output = flag.String("o", "", "Write to this file instead of stdout")
+	flag.Parse()
+
+	if err := run(*day, *size, *seed, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(day, size int, seed int64, output string) (err error) {
+	var w io.Writer = os.Stdout
+	if output != "" {
+		file, createErr := os.Create(output)
+		if createErr != nil {
+			return createErr
+		}
+		// A failed close can leave the file truncated, so it fails the run
+		defer func() {
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+		w = file
+	}
+
+	return gen.Generate(w, day, size, seed)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+
+	"advent-of-code-2024/internal/trace"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+	FormatHTML = "html" // only views that render markup support it
+)
+
+func validateFormat(format string) error {
+	if format != FormatText && format != FormatJSON && format != FormatHTML {
+		return fmt.Errorf("format must be %s, %s or %s", FormatText, FormatJSON, FormatHTML)
+	}
+	return nil
+}
+
+// explainer prints the trace events emitted by solvers, either as a readable
+// narrative grouped by puzzle or as one JSON object per line
+type explainer struct {
+	mu        sync.Mutex
+	w         io.Writer
+	format    string
+	day, part int
+}
+
+func newExplainer(w io.Writer, format string) *explainer {
+	return &explainer{w: w, format: format}
+}
+
+// record is installed as the trace hook; solvers may call it concurrently
+func (e *explainer) record(event trace.Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.format == FormatJSON {
+		json.NewEncoder(e.w).Encode(event)
+		return
+	}
+
+	if event.Day != e.day || event.Part != e.part {
+		e.day, e.part = event.Day, event.Part
+		fmt.Fprintf(e.w, "\nDay %d, Part %d\n", event.Day, event.Part)
+	}
+	fmt.Fprintf(e.w, "  %s\n", event.Message)
+}
+
+// explainedResult is the JSON form of a PuzzleResult
+type explainedResult struct {
+	Day      int      `json:"day"`
+	Part     int      `json:"part"`
+	Kind     string   `json:"kind"`
+	Result   *big.Int `json:"result"`
+	Duration string   `json:"duration"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// writeResults ends a JSON explanation with one result object per puzzle, in
+// place of the results table
+func (e *explainer) writeResults(results []PuzzleResult) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	encoder := json.NewEncoder(e.w)
+	for _, r := range results {
+		out := explainedResult{Day: r.Day, Part: r.Part, Kind: "result", Result: r.Result, Duration: r.Duration.String()}
+		if r.Error != nil {
+			out.Result, out.Error = new(big.Int), r.Error.Error()
+		}
+		encoder.Encode(out)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"advent-of-code-2024/internal/trace"
+)
+
+func TestValidateFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantErr bool
+	}{
+		{FormatText, false},
+		{FormatJSON, false},
+		{FormatHTML, false},
+		{"", true},
+		{"xml", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			err := validateFormat(tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExplainerText(t *testing.T) {
+	var out bytes.Buffer
+	e := newExplainer(&out, FormatText)
+
+	e.record(trace.Event{Day: 1, Part: 1, Message: "first"})
+	e.record(trace.Event{Day: 1, Part: 1, Message: "second"})
+	e.record(trace.Event{Day: 1, Part: 2, Message: "third"})
+
+	expected := "\nDay 1, Part 1\n  first\n  second\n\nDay 1, Part 2\n  third\n"
+	if out.String() != expected {
+		t.Errorf("explainer output = %q, want %q", out.String(), expected)
+	}
+}
+
+func TestExplainerJSON(t *testing.T) {
+	var out bytes.Buffer
+	e := newExplainer(&out, FormatJSON)
+
+	e.record(trace.Event{Day: 3, Part: 2, Kind: "instruction", Message: "mul(2,4) = 8", Fields: map[string]any{"product": 8}})
+	e.writeResults([]PuzzleResult{
+		{Day: 3, Part: 2, Result: big.NewInt(48), Duration: time.Millisecond},
+		{Day: 3, Part: 1, Error: errors.New("boom")},
+	})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 JSON lines, got %d: %q", len(lines), out.String())
+	}
+
+	var event trace.Event
+	if err := json.Unmarshal([]byte(lines[0]), &event); err != nil {
+		t.Fatalf("Invalid JSON event %q: %v", lines[0], err)
+	}
+	if event.Day != 3 || event.Kind != "instruction" || event.Fields["product"] != float64(8) {
+		t.Errorf("Unexpected decoded event %+v", event)
+	}
+
+	var result explainedResult
+	if err := json.Unmarshal([]byte(lines[2]), &result); err != nil {
+		t.Fatalf("Invalid JSON result %q: %v", lines[2], err)
+	}
+	if result.Kind != "result" || result.Error != "boom" {
+		t.Errorf("Unexpected decoded result %+v", result)
+	}
+}
@@ -0,0 +1,198 @@
+// Package checked provides integer arithmetic that detects overflow instead of
+// silently wrapping.
+//
+// Add, Sub and Mul report whether their int result is exact. Sum accumulates a
+// total and, depending on its Mode, either fails with ErrOverflow once the total
+// no longer fits in an int or carries on exactly using math/big.
+package checked
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// ErrOverflow is returned when a result does not fit in an int
+var ErrOverflow = errors.New("integer overflow")
+
+// Mode selects what happens when a calculation overflows int
+type Mode int
+
+const (
+	// Strict fails with ErrOverflow
+	Strict Mode = iota
+	// Promote continues the calculation with math/big
+	Promote
+)
+
+func (m Mode) String() string {
+	switch m {
+	case Strict:
+		return "strict"
+	case Promote:
+		return "promote"
+	default:
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+}
+
+// ParseMode reads a mode from its String form
+func ParseMode(name string) (Mode, error) {
+	for _, mode := range []Mode{Strict, Promote} {
+		if name == mode.String() {
+			return mode, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown overflow mode %q, expected %s or %s", name, Strict, Promote)
+}
+
+// Add returns a+b and whether it fits in an int
+func Add(a, b int) (int, bool) {
+	c := a + b
+	// Overflow only happens when both operands have the same sign and the result does not
+	return c, (c > a) == (b > 0)
+}
+
+// Sub returns a-b and whether it fits in an int
+func Sub(a, b int) (int, bool) {
+	c := a - b
+	return c, (c < a) == (b > 0)
+}
+
+// Mul returns a*b and whether it fits in an int
+func Mul(a, b int) (int, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	if (a == -1 && b == math.MinInt) || (b == -1 && a == math.MinInt) {
+		return c, false
+	}
+	return c, c/b == a
+}
+
+// Sum is a running total. The zero value is an empty Strict sum.
+type Sum struct {
+	mode  Mode
+	small int
+	big   *big.Int // the exact total once it has outgrown int (Promote only)
+	err   error
+}
+
+// NewSum returns an empty sum that handles overflow according to mode
+func NewSum(mode Mode) *Sum {
+	return &Sum{mode: mode}
+}
+
+// Add adds v to the total
+func (s *Sum) Add(v int) {
+	if s.err != nil {
+		return
+	}
+	if s.big != nil {
+		s.big.Add(s.big, big.NewInt(int64(v)))
+		return
+	}
+
+	if total, ok := Add(s.small, v); ok {
+		s.small = total
+		return
+	}
+	s.AddBig(big.NewInt(int64(v)))
+}
+
+// AddProduct adds a*b to the total
+func (s *Sum) AddProduct(a, b int) {
+	if product, ok := Mul(a, b); ok {
+		s.Add(product)
+		return
+	}
+	s.AddBig(new(big.Int).Mul(big.NewInt(int64(a)), big.NewInt(int64(b))))
+}
+
+// AddAbsDiff adds |a-b| to the total
+func (s *Sum) AddAbsDiff(a, b int) {
+	if diff, ok := Sub(a, b); ok && diff != math.MinInt {
+		s.Add(max(diff, -diff))
+		return
+	}
+	diff := new(big.Int).Sub(big.NewInt(int64(a)), big.NewInt(int64(b)))
+	s.AddBig(diff.Abs(diff))
+}
+
+// AddBig adds an arbitrarily large v to the total
+func (s *Sum) AddBig(v *big.Int) {
+	if s.err != nil {
+		return
+	}
+	if s.big == nil {
+		if v.IsInt64() {
+			if total, ok := Add(s.small, int(v.Int64())); ok {
+				s.small = total
+				return
+			}
+		}
+		if s.mode == Strict {
+			s.err = fmt.Errorf("%w: %d + %s", ErrOverflow, s.small, v)
+			return
+		}
+		s.big = big.NewInt(int64(s.small))
+	}
+	s.big.Add(s.big, v)
+}
+
+// Err returns the overflow that stopped a Strict sum, if any
+func (s *Sum) Err() error {
+	return s.err
+}
+
+// Int returns the total, or an error if it overflowed or does not fit in an int
+func (s *Sum) Int() (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	if s.big != nil {
+		if !s.big.IsInt64() {
+			return 0, fmt.Errorf("%w: total %s", ErrOverflow, s.big)
+		}
+		return int(s.big.Int64()), nil
+	}
+	return s.small, nil
+}
+
+// Big returns the exact total, or the overflow error of a Strict sum
+func (s *Sum) Big() (*big.Int, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.big != nil {
+		return new(big.Int).Set(s.big), nil
+	}
+	return big.NewInt(int64(s.small)), nil
+}
+
+// Value returns the total as an int, or as a *big.Int once it has been
+// promoted, for use in trace fields and JSON. It is nil after an overflow.
+func (s *Sum) Value() any {
+	switch {
+	case s.err != nil:
+		return nil
+	case s.big != nil:
+		return new(big.Int).Set(s.big)
+	default:
+		return s.small
+	}
+}
+
+// String formats the total, or "overflow" once a Strict sum has failed
+func (s *Sum) String() string {
+	switch {
+	case s.err != nil:
+		return "overflow"
+	case s.big != nil:
+		return s.big.String()
+	default:
+		return fmt.Sprint(s.small)
+	}
+}
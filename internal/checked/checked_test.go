@@ -0,0 +1,167 @@
+package checked
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestAdd(t *testing.T) {
+	tests := []struct {
+		a, b     int
+		expected int
+		ok       bool
+	}{
+		{1, 2, 3, true},
+		{-5, 3, -2, true},
+		{math.MaxInt, 0, math.MaxInt, true},
+		{math.MaxInt, 1, 0, false},
+		{math.MinInt, -1, 0, false},
+		{math.MinInt, math.MaxInt, -1, true},
+	}
+
+	for _, tt := range tests {
+		got, ok := Add(tt.a, tt.b)
+		if ok != tt.ok || (ok && got != tt.expected) {
+			t.Errorf("Add(%d, %d) = %d, %t, expected %d, %t", tt.a, tt.b, got, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestSub(t *testing.T) {
+	tests := []struct {
+		a, b     int
+		expected int
+		ok       bool
+	}{
+		{3, 5, -2, true},
+		{math.MinInt, 0, math.MinInt, true},
+		{math.MinInt, 1, 0, false},
+		{math.MaxInt, -1, 0, false},
+		{0, math.MinInt, 0, false},
+		{-1, math.MinInt, math.MaxInt, true},
+	}
+
+	for _, tt := range tests {
+		got, ok := Sub(tt.a, tt.b)
+		if ok != tt.ok || (ok && got != tt.expected) {
+			t.Errorf("Sub(%d, %d) = %d, %t, expected %d, %t", tt.a, tt.b, got, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestMul(t *testing.T) {
+	tests := []struct {
+		a, b     int
+		expected int
+		ok       bool
+	}{
+		{6, 7, 42, true},
+		{-6, 7, -42, true},
+		{0, math.MinInt, 0, true},
+		{math.MaxInt, 1, math.MaxInt, true},
+		{math.MaxInt, 2, 0, false},
+		{math.MinInt, -1, 0, false},
+		{-1, math.MinInt, 0, false},
+		{1 << 32, 1 << 31, 0, false},
+		{1 << 31, 1 << 31, 1 << 62, true},
+	}
+
+	for _, tt := range tests {
+		got, ok := Mul(tt.a, tt.b)
+		if ok != tt.ok || (ok && got != tt.expected) {
+			t.Errorf("Mul(%d, %d) = %d, %t, expected %d, %t", tt.a, tt.b, got, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestSumStrict(t *testing.T) {
+	var sum Sum
+	sum.Add(math.MaxInt - 1)
+	sum.Add(1)
+	if got, err := sum.Int(); err != nil || got != math.MaxInt {
+		t.Fatalf("Int() = %d, %v, expected %d", got, err, math.MaxInt)
+	}
+
+	sum.Add(1)
+	if !errors.Is(sum.Err(), ErrOverflow) {
+		t.Errorf("Err() = %v, expected ErrOverflow", sum.Err())
+	}
+	if _, err := sum.Int(); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Int() error = %v, expected ErrOverflow", err)
+	}
+	if _, err := sum.Big(); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Big() error = %v, expected ErrOverflow", err)
+	}
+
+	// Further additions are ignored once the sum has failed
+	sum.Add(-10)
+	if sum.String() != "overflow" || sum.Value() != nil {
+		t.Errorf("failed sum = %s (%v), expected overflow", sum.String(), sum.Value())
+	}
+}
+
+func TestModeString(t *testing.T) {
+	if Strict.String() != "strict" || Promote.String() != "promote" || Mode(7).String() != "Mode(7)" {
+		t.Errorf("unexpected mode names %s, %s, %s", Strict, Promote, Mode(7))
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for _, mode := range []Mode{Strict, Promote} {
+		if parsed, err := ParseMode(mode.String()); err != nil || parsed != mode {
+			t.Errorf("ParseMode(%q) = %v, %v, expected %v", mode.String(), parsed, err, mode)
+		}
+	}
+	if _, err := ParseMode("wrap"); err == nil {
+		t.Error("ParseMode(\"wrap\") expected error")
+	}
+}
+
+func TestSumPromote(t *testing.T) {
+	sum := NewSum(Promote)
+	sum.AddProduct(math.MaxInt, 4)
+	sum.AddAbsDiff(math.MinInt, math.MaxInt)
+	sum.Add(3)
+
+	expected := new(big.Int).Mul(big.NewInt(math.MaxInt64), big.NewInt(4))
+	expected.Add(expected, new(big.Int).Sub(big.NewInt(math.MaxInt64), big.NewInt(math.MinInt64)))
+	expected.Add(expected, big.NewInt(3))
+
+	got, err := sum.Big()
+	if err != nil {
+		t.Fatalf("Big() error = %v", err)
+	}
+	if got.Cmp(expected) != 0 {
+		t.Errorf("Big() = %s, expected %s", got, expected)
+	}
+	if _, err := sum.Int(); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Int() error = %v, expected ErrOverflow for a total past int64", err)
+	}
+	if sum.String() != expected.String() {
+		t.Errorf("String() = %s, expected %s", sum.String(), expected)
+	}
+
+	// A promoted total that comes back into range converts to int again
+	sum.AddBig(new(big.Int).Neg(expected))
+	sum.Add(7)
+	if got, err := sum.Int(); err != nil || got != 7 {
+		t.Errorf("Int() = %d, %v, expected 7", got, err)
+	}
+}
+
+func TestSumAddAbsDiff(t *testing.T) {
+	var sum Sum
+	sum.AddAbsDiff(3, 10)
+	sum.AddAbsDiff(10, 3)
+	sum.AddAbsDiff(-4, 4)
+	if got, err := sum.Int(); err != nil || got != 22 {
+		t.Errorf("Int() = %d, %v, expected 22", got, err)
+	}
+
+	sum.AddAbsDiff(math.MinInt, 0)
+	if !errors.Is(sum.Err(), ErrOverflow) {
+		t.Errorf("Err() = %v, expected ErrOverflow for |MinInt|", sum.Err())
+	}
+}
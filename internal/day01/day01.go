@@ -24,12 +24,14 @@
 package day01
 
 import (
-	"bufio"
 	"fmt"
+	"io"
+	"math/big"
 	"os"
 	"sort"
-	"strconv"
-	"strings"
+
+	"advent-of-code-2024/internal/checked"
+	"advent-of-code-2024/internal/trace"
 )
 
 func parseInput(filename string) ([]int, []int, error) {
@@ -39,39 +41,25 @@ func parseInput(filename string) ([]int, []int, error) {
 	}
 	defer file.Close()
 
-	var left, right []int
-	scanner := bufio.NewScanner(file)
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
-		}
-
-		parts := strings.Fields(line)
-		if len(parts) != 2 {
-			return nil, nil, fmt.Errorf("invalid line format: %s", line)
-		}
-
-		leftNum, err := strconv.Atoi(parts[0])
-		if err != nil {
-			return nil, nil, fmt.Errorf("invalid left number: %s", parts[0])
-		}
-
-		rightNum, err := strconv.Atoi(parts[1])
-		if err != nil {
-			return nil, nil, fmt.Errorf("invalid right number: %s", parts[1])
-		}
-
-		left = append(left, leftNum)
-		right = append(right, rightNum)
-	}
+	return parseReader(file)
+}
 
-	if err := scanner.Err(); err != nil {
+// parseReader reads the two whitespace-separated columns from r; the puzzle's
+// special case of parseColumns
+func parseReader(r io.Reader) ([]int, []int, error) {
+	columns, err := parseColumns(r)
+	if err != nil {
 		return nil, nil, err
 	}
 
-	return left, right, nil
+	switch len(columns) {
+	case 0:
+		return nil, nil, nil
+	case 2:
+		return columns[0], columns[1], nil
+	default:
+		return nil, nil, fmt.Errorf("invalid line format: expected 2 columns, found %d", len(columns))
+	}
 }
 
 func calculateDistance(a, b int) int {
@@ -82,13 +70,32 @@ func calculateDistance(a, b int) int {
 }
 
 func calculateTotalDistance(left, right []int) (int, error) {
+	total, err := sumDistances(left, right, checked.Strict)
+	if err != nil {
+		return 0, err
+	}
+
+	return total.Int()
+}
+
+// sumDistances adds up the distance of each pair, handling overflow according to mode
+func sumDistances(left, right []int, mode checked.Mode) (*checked.Sum, error) {
 	if len(left) != len(right) {
-		return 0, fmt.Errorf("slice length mismatch: left has %d elements, right has %d elements", len(left), len(right))
+		return nil, fmt.Errorf("slice length mismatch: left has %d elements, right has %d elements", len(left), len(right))
 	}
 
-	total := 0
+	total := checked.NewSum(mode)
 	for i := 0; i < len(left); i++ {
-		total += calculateDistance(left[i], right[i])
+		total.AddAbsDiff(left[i], right[i])
+		if err := total.Err(); err != nil {
+			return nil, fmt.Errorf("pair %d (%d, %d): %w", i+1, left[i], right[i], err)
+		}
+
+		if trace.Enabled() {
+			distance := calculateDistance(left[i], right[i])
+			trace.Emit("pair", fmt.Sprintf("pair %d: |%d - %d| = %d (running total %s)", i+1, left[i], right[i], distance, total),
+				map[string]any{"index": i, "left": left[i], "right": right[i], "distance": distance, "total": total.Value()})
+		}
 	}
 
 	return total, nil
@@ -99,11 +106,31 @@ func calculateTotalDistance(left, right []int) (int, error) {
 // then the second smallest, and so on, calculating the absolute difference for each
 // pair and returning the sum of all distances.
 func SolvePart1(filename string) (int, error) {
-	left, right, err := parseInput(filename)
+	total, err := solvePart1(filename, checked.Strict)
 	if err != nil {
 		return 0, err
 	}
 
+	return total.Int()
+}
+
+// SolvePart1Mode is SolvePart1 with a choice of overflow behaviour. In Promote
+// mode the exact total is returned however large it grows.
+func SolvePart1Mode(filename string, mode checked.Mode) (*big.Int, error) {
+	total, err := solvePart1(filename, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	return total.Big()
+}
+
+func solvePart1(filename string, mode checked.Mode) (*checked.Sum, error) {
+	left, right, err := parseInput(filename)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create copies for sorting to avoid modifying original slices
 	leftSorted := make([]int, len(left))
 	rightSorted := make([]int, len(right))
@@ -113,12 +140,7 @@ func SolvePart1(filename string) (int, error) {
 	sort.Ints(leftSorted)
 	sort.Ints(rightSorted)
 
-	total, err := calculateTotalDistance(leftSorted, rightSorted)
-	if err != nil {
-		return 0, err
-	}
-
-	return total, nil
+	return sumDistances(leftSorted, rightSorted, mode)
 }
 
 func countFrequencies(list []int) map[int]int {
@@ -131,16 +153,35 @@ func countFrequencies(list []int) map[int]int {
 	return frequencies
 }
 
-func calculateSimilarityScore(left, right []int) int {
+func calculateSimilarityScore(left, right []int) (int, error) {
+	total, err := sumSimilarity(left, right, checked.Strict)
+	if err != nil {
+		return 0, err
+	}
+
+	return total.Int()
+}
+
+// sumSimilarity adds up each left number times its right-list frequency,
+// handling overflow according to mode
+func sumSimilarity(left, right []int, mode checked.Mode) (*checked.Sum, error) {
 	frequencies := countFrequencies(right)
 
-	totalScore := 0
+	totalScore := checked.NewSum(mode)
 	for _, num := range left {
 		count := frequencies[num]
-		totalScore += num * count
+		totalScore.AddProduct(num, count)
+		if err := totalScore.Err(); err != nil {
+			return nil, fmt.Errorf("%d appearing %d times: %w", num, count, err)
+		}
+
+		if trace.Enabled() {
+			trace.Emit("similarity", fmt.Sprintf("%d appears %d times in the right list: %d * %d = %d (running total %s)", num, count, num, count, num*count, totalScore),
+				map[string]any{"left": num, "count": count, "score": num * count, "total": totalScore.Value()})
+		}
 	}
 
-	return totalScore
+	return totalScore, nil
 }
 
 // SolvePart2 solves part 2 of the Day 1 puzzle by calculating a similarity score.
@@ -153,5 +194,21 @@ func SolvePart2(filename string) (int, error) {
 		return 0, err
 	}
 
-	return calculateSimilarityScore(left, right), nil
+	return calculateSimilarityScore(left, right)
+}
+
+// SolvePart2Mode is SolvePart2 with a choice of overflow behaviour. In Promote
+// mode the exact score is returned however large it grows.
+func SolvePart2Mode(filename string, mode checked.Mode) (*big.Int, error) {
+	left, right, err := parseInput(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := sumSimilarity(left, right, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	return total.Big()
 }
@@ -0,0 +1,48 @@
+package day01
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"advent-of-code-2024/internal/gen"
+)
+
+func benchmarkInput(b *testing.B) (string, string) {
+	input, err := gen.String(1, 100000, 1)
+	if err != nil {
+		b.Fatal(err)
+	}
+	path := b.TempDir() + "/input.txt"
+	if err := os.WriteFile(path, []byte(input), 0o644); err != nil {
+		b.Fatal(err)
+	}
+	return input, path
+}
+
+func BenchmarkSolveBothParts(b *testing.B) {
+	_, path := benchmarkInput(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := SolvePart1(path); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := SolvePart2(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSolveStream(b *testing.B) {
+	input, _ := benchmarkInput(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := SolveStream(strings.NewReader(input)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package day01
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"advent-of-code-2024/internal/checked"
+)
+
+func FuzzParseInput(f *testing.F) {
+	example, err := os.ReadFile("example-input.txt")
+	if err != nil {
+		f.Fatalf("failed to read example input: %v", err)
+	}
+	f.Add(string(example))
+	f.Add("")
+	f.Add("1 2\n3")
+	f.Add("a b")
+	f.Add("  7\t8  \n\n9 10\n")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		left, right, err := parseReader(strings.NewReader(data))
+		if err != nil {
+			return
+		}
+		if len(left) != len(right) {
+			t.Fatalf("parseReader returned %d left and %d right values", len(left), len(right))
+		}
+	})
+}
+
+func FuzzSolve(f *testing.F) {
+	example, err := os.ReadFile("example-input.txt")
+	if err != nil {
+		f.Fatalf("failed to read example input: %v", err)
+	}
+	f.Add(string(example))
+	f.Add("5 5\n5 5")
+	f.Add("-3 3\n0 0")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		left, right, err := parseReader(strings.NewReader(data))
+		if err != nil {
+			return
+		}
+
+		sort.Ints(left)
+		sort.Ints(right)
+		// Overflow is the only way a parsed input may fail
+		if _, err := calculateTotalDistance(left, right); err != nil && !errors.Is(err, checked.ErrOverflow) {
+			t.Fatalf("calculateTotalDistance failed on parsed input: %v", err)
+		}
+		if _, err := calculateSimilarityScore(left, right); err != nil && !errors.Is(err, checked.ErrOverflow) {
+			t.Fatalf("calculateSimilarityScore failed on parsed input: %v", err)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package day01
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"advent-of-code-2024/internal/difftest"
+)
+
+// referenceLists parses the two columns without any shared helpers
+func referenceLists(input string) ([]int, []int, error) {
+	var left, right []int
+	for _, line := range strings.Split(input, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, nil, fmt.Errorf("invalid line: %q", line)
+		}
+		l, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, nil, err
+		}
+		r, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, nil, err
+		}
+		left = append(left, l)
+		right = append(right, r)
+	}
+	return left, right, nil
+}
+
+// referencePart1 repeatedly removes the smallest value from each list and sums
+// the distance between them
+func referencePart1(input string) (int, error) {
+	left, right, err := referenceLists(input)
+	if err != nil {
+		return 0, err
+	}
+
+	takeSmallest := func(list []int) (int, []int) {
+		smallest := 0
+		for i := range list {
+			if list[i] < list[smallest] {
+				smallest = i
+			}
+		}
+		value := list[smallest]
+		return value, append(list[:smallest], list[smallest+1:]...)
+	}
+
+	total := 0
+	for len(left) > 0 {
+		var l, r int
+		l, left = takeSmallest(left)
+		r, right = takeSmallest(right)
+		if l > r {
+			total += l - r
+		} else {
+			total += r - l
+		}
+	}
+	return total, nil
+}
+
+// referencePart2 counts matches in the right list separately for every left value
+func referencePart2(input string) (int, error) {
+	left, right, err := referenceLists(input)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, l := range left {
+		for _, r := range right {
+			if l == r {
+				total += l
+			}
+		}
+	}
+	return total, nil
+}
+
+func TestDifferential(t *testing.T) {
+	difftest.Run(t, 1, []int{1, 10, 200},
+		difftest.Part{Solve: SolvePart1, Reference: referencePart1},
+		difftest.Part{Solve: SolvePart2, Reference: referencePart2})
+}
@@ -1,7 +1,16 @@
 package day01
 
 import (
+	"errors"
+	"math"
+	"math/big"
+	"os"
+	"strings"
 	"testing"
+
+	"advent-of-code-2024/internal/checked"
+
+	"advent-of-code-2024/internal/trace"
 )
 
 func TestParseInput(t *testing.T) {
@@ -123,7 +132,10 @@ func TestCalculateSimilarityScore(t *testing.T) {
 	right := []int{4, 3, 5, 3, 9, 3}
 	expected := 31
 
-	result := calculateSimilarityScore(left, right)
+	result, err := calculateSimilarityScore(left, right)
+	if err != nil {
+		t.Fatalf("calculateSimilarityScore failed: %v", err)
+	}
 	if result != expected {
 		t.Errorf("calculateSimilarityScore() = %d, expected %d", result, expected)
 	}
@@ -141,3 +153,76 @@ func TestSolvePart2(t *testing.T) {
 		t.Errorf("SolvePart2() = %d, expected %d", result, expected)
 	}
 }
+
+func TestTraceEvents(t *testing.T) {
+	var events []trace.Event
+	trace.SetHook(func(e trace.Event) { events = append(events, e) })
+	defer trace.SetHook(nil)
+
+	if _, err := SolvePart1("example-input.txt"); err != nil {
+		t.Fatalf("SolvePart1 failed: %v", err)
+	}
+
+	if len(events) != 6 {
+		t.Fatalf("Expected 6 pair events, got %d", len(events))
+	}
+	last := events[len(events)-1]
+	if last.Kind != "pair" || last.Fields["distance"] != 5 || last.Fields["total"] != 11 {
+		t.Errorf("Unexpected final pair event %+v", last)
+	}
+
+	events = nil
+	if _, err := SolvePart2("example-input.txt"); err != nil {
+		t.Fatalf("SolvePart2 failed: %v", err)
+	}
+
+	if len(events) != 6 {
+		t.Fatalf("Expected 6 similarity events, got %d", len(events))
+	}
+	if first := events[0]; first.Kind != "similarity" || first.Fields["count"] != 3 || first.Fields["score"] != 9 {
+		t.Errorf("Unexpected first similarity event %+v", first)
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	// Every value fits in an int, but the distances add up to 2 * MaxInt64 and
+	// 2^62 appearing twice in each list scores 2^64
+	input := "0 9223372036854775807\n0 9223372036854775807\n" +
+		"4611686018427387904 4611686018427387904\n4611686018427387904 4611686018427387904\n"
+	path := t.TempDir() + "/input.txt"
+	if err := os.WriteFile(path, []byte(input), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := SolvePart1(path); !errors.Is(err, checked.ErrOverflow) {
+		t.Errorf("SolvePart1() error = %v, expected ErrOverflow", err)
+	}
+	if _, err := SolvePart2(path); !errors.Is(err, checked.ErrOverflow) {
+		t.Errorf("SolvePart2() error = %v, expected ErrOverflow", err)
+	}
+	if _, err := SolveStream(strings.NewReader(input)); !errors.Is(err, checked.ErrOverflow) {
+		t.Errorf("SolveStream() error = %v, expected ErrOverflow", err)
+	}
+
+	twoPow62 := new(big.Int).Lsh(big.NewInt(1), 62)
+
+	// Sorted pairs: (0, 2^62) twice and (2^62, MaxInt64) twice
+	part1, err := SolvePart1Mode(path, checked.Promote)
+	if err != nil {
+		t.Fatalf("SolvePart1Mode() error = %v", err)
+	}
+	expected := new(big.Int).Mul(big.NewInt(math.MaxInt64), big.NewInt(2))
+	if part1.Cmp(expected) != 0 {
+		t.Errorf("SolvePart1Mode() = %s, expected %s", part1, expected)
+	}
+
+	// Both left copies of 2^62 match two right copies: 2 * 2 * 2^62 = 2^64
+	part2, err := SolvePart2Mode(path, checked.Promote)
+	if err != nil {
+		t.Fatalf("SolvePart2Mode() error = %v", err)
+	}
+	expected = new(big.Int).Mul(twoPow62, big.NewInt(4))
+	if part2.Cmp(expected) != 0 {
+		t.Errorf("SolvePart2Mode() = %s, expected %s", part2, expected)
+	}
+}
@@ -0,0 +1,168 @@
+package day01
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"advent-of-code-2024/internal/checked"
+)
+
+// Matrices compares every pair of lists. Distance[i][j] is the part 1 total
+// distance between lists i and j after sorting both, and Similarity[i][j] is the
+// part 2 score of list i counted against list j. For the puzzle's two columns,
+// Distance[0][1] and Similarity[0][1] are the answers to parts 1 and 2.
+type Matrices struct {
+	Distance   [][]int `json:"distance"`
+	Similarity [][]int `json:"similarity"`
+}
+
+// ReadColumns reads a file of K whitespace-separated columns as K lists
+func ReadColumns(filename string) ([][]int, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseColumns(file)
+}
+
+// ReadLists reads each file as separate lists. A file with one number per line
+// is one list; a file with several columns adds one list per column.
+func ReadLists(filenames ...string) ([][]int, error) {
+	var lists [][]int
+	for _, filename := range filenames {
+		columns, err := ReadColumns(filename)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+		lists = append(lists, columns...)
+	}
+
+	return lists, nil
+}
+
+// parseColumns reads whitespace-separated columns from r. The first non-empty
+// line sets the number of columns and every other line must match it.
+func parseColumns(r io.Reader) ([][]int, error) {
+	var columns [][]int
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		parts := strings.Fields(line)
+		if columns == nil {
+			columns = make([][]int, len(parts))
+		}
+		if len(parts) != len(columns) {
+			return nil, fmt.Errorf("invalid line format: expected %d columns: %s", len(columns), line)
+		}
+
+		for i, part := range parts {
+			num, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number in column %d: %s", i+1, part)
+			}
+			columns[i] = append(columns[i], num)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return columns, nil
+}
+
+// CompareLists builds the distance and similarity matrices for lists. Sorted
+// pairing needs every list to be the same length.
+func CompareLists(lists [][]int) (*Matrices, error) {
+	sorted := make([][]int, len(lists))
+	for i, list := range lists {
+		if len(list) != len(lists[0]) {
+			return nil, fmt.Errorf("list length mismatch: list 1 has %d elements, list %d has %d elements", len(lists[0]), i+1, len(list))
+		}
+		sorted[i] = slices.Clone(list)
+		sort.Ints(sorted[i])
+	}
+
+	matrices := &Matrices{
+		Distance:   make([][]int, len(lists)),
+		Similarity: make([][]int, len(lists)),
+	}
+	for i := range lists {
+		matrices.Distance[i] = make([]int, len(lists))
+		matrices.Similarity[i] = make([]int, len(lists))
+	}
+
+	for i := range lists {
+		for j := range lists {
+			// Distance is symmetric, so each pair is only summed once
+			if j > i {
+				total, err := sumDistances(sorted[i], sorted[j], checked.Strict)
+				if err != nil {
+					return nil, fmt.Errorf("lists %d and %d: %w", i+1, j+1, err)
+				}
+				if matrices.Distance[i][j], err = total.Int(); err != nil {
+					return nil, fmt.Errorf("lists %d and %d: %w", i+1, j+1, err)
+				}
+				matrices.Distance[j][i] = matrices.Distance[i][j]
+			}
+
+			score, err := calculateSimilarityScore(lists[i], lists[j])
+			if err != nil {
+				return nil, fmt.Errorf("lists %d and %d: %w", i+1, j+1, err)
+			}
+			matrices.Similarity[i][j] = score
+		}
+	}
+
+	return matrices, nil
+}
+
+// WriteTable writes both matrices as plain-text tables
+func (m *Matrices) WriteTable(w io.Writer) error {
+	var b strings.Builder
+
+	b.WriteString("Sorted distance\n")
+	writeMatrix(&b, m.Distance)
+
+	b.WriteString("\nSimilarity (row list scored against column list)\n")
+	writeMatrix(&b, m.Similarity)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeMatrix(b *strings.Builder, matrix [][]int) {
+	width := len("list 00")
+	for _, row := range matrix {
+		for _, value := range row {
+			width = max(width, len(strconv.Itoa(value)))
+		}
+	}
+
+	fmt.Fprintf(b, "%*s", width, "")
+	for j := range matrix {
+		fmt.Fprintf(b, "  %*s", width, fmt.Sprintf("list %d", j+1))
+	}
+	b.WriteString("\n")
+
+	for i, row := range matrix {
+		fmt.Fprintf(b, "%*s", width, fmt.Sprintf("list %d", i+1))
+		for _, value := range row {
+			fmt.Fprintf(b, "  %*d", width, value)
+		}
+		b.WriteString("\n")
+	}
+}
@@ -0,0 +1,140 @@
+package day01
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseColumns(t *testing.T) {
+	columns, err := parseColumns(strings.NewReader("1 2 3\n\n4   5\t6\n"))
+	if err != nil {
+		t.Fatalf("parseColumns failed: %v", err)
+	}
+
+	expected := [][]int{{1, 4}, {2, 5}, {3, 6}}
+	if !reflect.DeepEqual(columns, expected) {
+		t.Errorf("parseColumns() = %v, expected %v", columns, expected)
+	}
+}
+
+func TestParseColumnsErrors(t *testing.T) {
+	tests := []string{
+		"1 2 3\n4 5\n",
+		"1 2\n3 x\n",
+	}
+
+	for _, input := range tests {
+		if _, err := parseColumns(strings.NewReader(input)); err == nil {
+			t.Errorf("parseColumns(%q) expected error", input)
+		}
+	}
+}
+
+func TestParseReaderRequiresTwoColumns(t *testing.T) {
+	if _, _, err := parseReader(strings.NewReader("1 2 3\n")); err == nil {
+		t.Error("parseReader should reject three columns")
+	}
+}
+
+func TestReadLists(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.txt":  "3\n4\n2\n",
+		"bc.txt": "4 1\n3 2\n5 3\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lists, err := ReadLists(filepath.Join(dir, "a.txt"), filepath.Join(dir, "bc.txt"))
+	if err != nil {
+		t.Fatalf("ReadLists failed: %v", err)
+	}
+
+	expected := [][]int{{3, 4, 2}, {4, 3, 5}, {1, 2, 3}}
+	if !reflect.DeepEqual(lists, expected) {
+		t.Errorf("ReadLists() = %v, expected %v", lists, expected)
+	}
+
+	if _, err := ReadLists(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Error("ReadLists should fail for a missing file")
+	}
+}
+
+func TestCompareListsExample(t *testing.T) {
+	lists, err := ReadColumns("example-input.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matrices, err := CompareLists(lists)
+	if err != nil {
+		t.Fatalf("CompareLists failed: %v", err)
+	}
+
+	// The off-diagonal entries are the puzzle answers
+	expectedDistance := [][]int{{0, 11}, {11, 0}}
+	expectedSimilarity := [][]int{{34, 31}, {31, 45}}
+	if !reflect.DeepEqual(matrices.Distance, expectedDistance) {
+		t.Errorf("Distance = %v, expected %v", matrices.Distance, expectedDistance)
+	}
+	if !reflect.DeepEqual(matrices.Similarity, expectedSimilarity) {
+		t.Errorf("Similarity = %v, expected %v", matrices.Similarity, expectedSimilarity)
+	}
+}
+
+func TestCompareListsThreeColumns(t *testing.T) {
+	lists := [][]int{{1, 2, 3}, {3, 2, 1}, {10, 10, 2}}
+
+	matrices, err := CompareLists(lists)
+	if err != nil {
+		t.Fatalf("CompareLists failed: %v", err)
+	}
+
+	expectedDistance := [][]int{{0, 0, 16}, {0, 0, 16}, {16, 16, 0}}
+	expectedSimilarity := [][]int{{6, 6, 2}, {6, 6, 2}, {2, 2, 42}}
+	if !reflect.DeepEqual(matrices.Distance, expectedDistance) {
+		t.Errorf("Distance = %v, expected %v", matrices.Distance, expectedDistance)
+	}
+	if !reflect.DeepEqual(matrices.Similarity, expectedSimilarity) {
+		t.Errorf("Similarity = %v, expected %v", matrices.Similarity, expectedSimilarity)
+	}
+}
+
+func TestCompareListsLengthMismatch(t *testing.T) {
+	if _, err := CompareLists([][]int{{1, 2}, {1}}); err == nil {
+		t.Error("CompareLists should fail for lists of different lengths")
+	}
+}
+
+func TestMatricesWriteTable(t *testing.T) {
+	matrices := &Matrices{
+		Distance:   [][]int{{0, 11}, {11, 0}},
+		Similarity: [][]int{{34, 31}, {31, 46}},
+	}
+
+	var out bytes.Buffer
+	if err := matrices.WriteTable(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `Sorted distance
+          list 1   list 2
+ list 1        0       11
+ list 2       11        0
+
+Similarity (row list scored against column list)
+          list 1   list 2
+ list 1       34       31
+ list 2       31       46
+`
+	if out.String() != expected {
+		t.Errorf("WriteTable() =\n%s\nexpected\n%s", out.String(), expected)
+	}
+}
@@ -0,0 +1,126 @@
+package day01
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Pair is one pairing of the k-th smallest left and right location IDs
+type Pair struct {
+	Left     int `json:"left"`
+	Right    int `json:"right"`
+	Distance int `json:"distance"`
+}
+
+// Frequency is how many times a value appears in the right list
+type Frequency struct {
+	Value int `json:"value"`
+	Count int `json:"count"`
+}
+
+// PairingReport explains both answers: the part 1 pairs and the part 2 frequencies
+type PairingReport struct {
+	Pairs            []Pair      `json:"pairs"`
+	TotalDistance    int         `json:"totalDistance"`
+	TopContributors  []Pair      `json:"topContributors"`
+	RightFrequencies []Frequency `json:"rightFrequencies"`
+	Unmatched        []int       `json:"unmatched"`
+	SimilarityScore  int         `json:"similarityScore"`
+}
+
+// BuildReport reads the lists from filename and builds a PairingReport listing the
+// topK pairs with the largest distances
+func BuildReport(filename string, topK int) (*PairingReport, error) {
+	left, right, err := parseInput(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPairingReport(left, right, topK)
+}
+
+func newPairingReport(left, right []int, topK int) (*PairingReport, error) {
+	if len(left) != len(right) {
+		return nil, fmt.Errorf("slice length mismatch: left has %d elements, right has %d elements", len(left), len(right))
+	}
+
+	leftSorted, rightSorted := slices.Clone(left), slices.Clone(right)
+	sort.Ints(leftSorted)
+	sort.Ints(rightSorted)
+
+	report := &PairingReport{
+		Pairs:            make([]Pair, len(left)),
+		RightFrequencies: []Frequency{},
+		Unmatched:        []int{},
+	}
+	for i := range leftSorted {
+		distance := calculateDistance(leftSorted[i], rightSorted[i])
+		report.Pairs[i] = Pair{Left: leftSorted[i], Right: rightSorted[i], Distance: distance}
+	}
+
+	var err error
+	if report.TotalDistance, err = calculateTotalDistance(leftSorted, rightSorted); err != nil {
+		return nil, err
+	}
+
+	// Largest distances first; the stable sort keeps ties in pairing order
+	contributors := slices.Clone(report.Pairs)
+	sort.SliceStable(contributors, func(i, j int) bool {
+		return contributors[i].Distance > contributors[j].Distance
+	})
+	report.TopContributors = contributors[:min(max(topK, 0), len(contributors))]
+
+	frequencies := countFrequencies(right)
+	for value, count := range frequencies {
+		report.RightFrequencies = append(report.RightFrequencies, Frequency{Value: value, Count: count})
+	}
+	sort.Slice(report.RightFrequencies, func(i, j int) bool {
+		return report.RightFrequencies[i].Value < report.RightFrequencies[j].Value
+	})
+
+	for _, num := range leftSorted {
+		if frequencies[num] == 0 {
+			report.Unmatched = append(report.Unmatched, num)
+		}
+	}
+	if report.SimilarityScore, err = calculateSimilarityScore(left, right); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// WriteTable writes the report as plain-text tables
+func (r *PairingReport) WriteTable(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Pairs (total distance %d)\n", r.TotalDistance)
+	writePairs(&b, r.Pairs)
+
+	fmt.Fprintf(&b, "\nTop %d contributors\n", len(r.TopContributors))
+	writePairs(&b, r.TopContributors)
+
+	fmt.Fprintf(&b, "\nRight list frequencies (similarity score %d)\n", r.SimilarityScore)
+	fmt.Fprintf(&b, "%10s  %5s\n", "Value", "Count")
+	for _, f := range r.RightFrequencies {
+		fmt.Fprintf(&b, "%10d  %5d  %s\n", f.Value, f.Count, strings.Repeat("#", min(f.Count, 40)))
+	}
+
+	fmt.Fprintf(&b, "\nLeft values with no match (%d)\n", len(r.Unmatched))
+	for _, num := range r.Unmatched {
+		fmt.Fprintf(&b, "%10d\n", num)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writePairs(b *strings.Builder, pairs []Pair) {
+	fmt.Fprintf(b, "%10s  %10s  %10s\n", "Left", "Right", "Distance")
+	for _, p := range pairs {
+		fmt.Fprintf(b, "%10d  %10d  %10d\n", p.Left, p.Right, p.Distance)
+	}
+}
@@ -0,0 +1,119 @@
+package day01
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBuildReport(t *testing.T) {
+	report, err := BuildReport("example-input.txt", 3)
+	if err != nil {
+		t.Fatalf("BuildReport() error = %v", err)
+	}
+
+	expectedPairs := []Pair{
+		{1, 3, 2}, {2, 3, 1}, {3, 3, 0}, {3, 4, 1}, {3, 5, 2}, {4, 9, 5},
+	}
+	if !slices.Equal(report.Pairs, expectedPairs) {
+		t.Errorf("Pairs = %v, expected %v", report.Pairs, expectedPairs)
+	}
+	if report.TotalDistance != 11 {
+		t.Errorf("TotalDistance = %d, expected 11", report.TotalDistance)
+	}
+
+	expectedTop := []Pair{{4, 9, 5}, {1, 3, 2}, {3, 5, 2}}
+	if !slices.Equal(report.TopContributors, expectedTop) {
+		t.Errorf("TopContributors = %v, expected %v", report.TopContributors, expectedTop)
+	}
+
+	expectedFrequencies := []Frequency{{3, 3}, {4, 1}, {5, 1}, {9, 1}}
+	if !slices.Equal(report.RightFrequencies, expectedFrequencies) {
+		t.Errorf("RightFrequencies = %v, expected %v", report.RightFrequencies, expectedFrequencies)
+	}
+
+	if expected := []int{1, 2}; !slices.Equal(report.Unmatched, expected) {
+		t.Errorf("Unmatched = %v, expected %v", report.Unmatched, expected)
+	}
+	if report.SimilarityScore != 31 {
+		t.Errorf("SimilarityScore = %d, expected 31", report.SimilarityScore)
+	}
+}
+
+func TestReportTopK(t *testing.T) {
+	tests := []struct {
+		topK     int
+		expected int
+	}{
+		{0, 0},
+		{-1, 0},
+		{2, 2},
+		{100, 6},
+	}
+
+	for _, tt := range tests {
+		report, err := BuildReport("example-input.txt", tt.topK)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(report.TopContributors) != tt.expected {
+			t.Errorf("BuildReport(topK=%d) returned %d contributors, expected %d", tt.topK, len(report.TopContributors), tt.expected)
+		}
+	}
+}
+
+func TestReportLengthMismatch(t *testing.T) {
+	if _, err := newPairingReport([]int{1, 2}, []int{1}, 1); err == nil {
+		t.Error("Expected error for mismatched list lengths")
+	}
+}
+
+func TestReportWriteTable(t *testing.T) {
+	report, err := BuildReport("example-input.txt", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := report.WriteTable(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"Pairs (total distance 11)",
+		"Top 1 contributors",
+		"         4           9           5",
+		"Right list frequencies (similarity score 31)",
+		"         3      3  ###",
+		"Left values with no match (2)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("WriteTable() output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestReportJSON(t *testing.T) {
+	report, err := BuildReport("example-input.txt", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded PairingReport
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.TotalDistance != 11 || decoded.SimilarityScore != 31 || len(decoded.Pairs) != 6 {
+		t.Errorf("JSON round trip = %+v", decoded)
+	}
+	if !strings.Contains(string(data), `"unmatched":[1,2]`) {
+		t.Errorf("JSON %s missing unmatched values", data)
+	}
+}
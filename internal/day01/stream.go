@@ -0,0 +1,318 @@
+package day01
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+
+	"advent-of-code-2024/internal/checked"
+)
+
+// maxDenseRange is the widest value range counted in a dense histogram. Wider
+// ranges fall back to a map of counts whose keys are radix sorted.
+const maxDenseRange = 1 << 20
+
+// StreamResult holds both puzzle answers computed by SolveStream
+type StreamResult struct {
+	Pairs      int // number of lines read
+	Distance   int // part 1 answer
+	Similarity int // part 2 answer
+}
+
+// SolveStream computes both parts in a single pass over r without keeping the
+// lists in memory. Each column is reduced to a histogram of value counts, so
+// memory depends on the number of distinct values rather than the number of
+// lines. Pairing the sorted lists then becomes a merge over the two histograms.
+func SolveStream(r io.Reader) (StreamResult, error) {
+	var left, right histogram
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		first, second, fields := splitFields(scanner.Bytes())
+		if fields == 0 {
+			continue
+		}
+		if fields != 2 {
+			return StreamResult{}, fmt.Errorf("invalid line format: %s", scanner.Text())
+		}
+
+		leftNum, err := parseInt(first)
+		if err != nil {
+			return StreamResult{}, fmt.Errorf("invalid left number: %s", first)
+		}
+		rightNum, err := parseInt(second)
+		if err != nil {
+			return StreamResult{}, fmt.Errorf("invalid right number: %s", second)
+		}
+
+		left.add(leftNum)
+		right.add(rightNum)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return StreamResult{}, err
+	}
+
+	leftBuckets, rightBuckets := left.buckets(), right.buckets()
+	distance, err := histogramDistance(leftBuckets, rightBuckets)
+	if err != nil {
+		return StreamResult{}, err
+	}
+	similarity, err := histogramSimilarity(leftBuckets, rightBuckets)
+	if err != nil {
+		return StreamResult{}, err
+	}
+
+	return StreamResult{Pairs: left.total, Distance: distance, Similarity: similarity}, nil
+}
+
+// bucket is one distinct value and how many times it occurred
+type bucket struct {
+	value, count int
+}
+
+// histogram counts values, densely over a window [min, min+len(counts)) while the
+// range allows, and in a map once it does not
+type histogram struct {
+	min    int
+	counts []uint32
+	sparse map[int]int
+	total  int
+}
+
+func (h *histogram) add(v int) {
+	h.total++
+
+	if h.sparse == nil && !h.fitDense(v) {
+		h.toSparse()
+	}
+	if h.sparse != nil {
+		h.sparse[v]++
+		return
+	}
+	h.counts[v-h.min]++
+}
+
+// fitDense grows the dense window to include v, doubling it to amortise the
+// copying, and reports false if v would push the range past maxDenseRange
+func (h *histogram) fitDense(v int) bool {
+	if h.counts == nil {
+		h.min, h.counts = v, make([]uint32, 1)
+		return true
+	}
+
+	// Work with inclusive bounds so that values near math.MaxInt cannot wrap
+	low, high := h.min, h.min+len(h.counts)-1
+	if v >= low && v <= high {
+		return true
+	}
+
+	newLow, newHigh := min(v, low), max(v, high)
+	if span, ok := checked.Sub(newHigh, newLow); !ok || span >= maxDenseRange {
+		return false
+	}
+
+	// Grow by at least the current size in the direction of v, within the limit
+	grow := min(len(h.counts), maxDenseRange-(newHigh-newLow+1))
+	if v < low && newLow-grow < newLow {
+		newLow -= grow
+	} else if v > high && newHigh+grow > newHigh {
+		newHigh += grow
+	}
+
+	counts := make([]uint32, newHigh-newLow+1)
+	copy(counts[low-newLow:], h.counts)
+	h.min, h.counts = newLow, counts
+	return true
+}
+
+func (h *histogram) toSparse() {
+	h.sparse = make(map[int]int)
+	for i, count := range h.counts {
+		if count > 0 {
+			h.sparse[h.min+i] = int(count)
+		}
+	}
+	h.counts = nil
+}
+
+// buckets returns the distinct values in ascending order with their counts.
+// The dense window is already ordered (a counting sort); sparse keys are radix sorted.
+func (h *histogram) buckets() []bucket {
+	if h.sparse == nil {
+		distinct := 0
+		for _, count := range h.counts {
+			if count > 0 {
+				distinct++
+			}
+		}
+
+		result := make([]bucket, 0, distinct)
+		for i, count := range h.counts {
+			if count > 0 {
+				result = append(result, bucket{value: h.min + i, count: int(count)})
+			}
+		}
+		return result
+	}
+
+	values := make([]int, 0, len(h.sparse))
+	for value := range h.sparse {
+		values = append(values, value)
+	}
+	radixSort(values)
+
+	result := make([]bucket, len(values))
+	for i, value := range values {
+		result[i] = bucket{value: value, count: h.sparse[value]}
+	}
+	return result
+}
+
+// histogramDistance pairs the k-th smallest values of both lists, as part 1 does
+// after sorting, but a whole run of equal pairs at a time
+func histogramDistance(left, right []bucket) (int, error) {
+	var total checked.Sum
+	i, j := 0, 0
+	var leftUsed, rightUsed int
+
+	for i < len(left) && j < len(right) {
+		pairs := min(left[i].count-leftUsed, right[j].count-rightUsed)
+		diff, ok := checked.Sub(left[i].value, right[j].value)
+		if !ok || diff == math.MinInt {
+			return 0, fmt.Errorf("%w: distance between %d and %d", checked.ErrOverflow, left[i].value, right[j].value)
+		}
+		total.AddProduct(pairs, max(diff, -diff))
+
+		leftUsed += pairs
+		rightUsed += pairs
+		if leftUsed == left[i].count {
+			i, leftUsed = i+1, 0
+		}
+		if rightUsed == right[j].count {
+			j, rightUsed = j+1, 0
+		}
+	}
+
+	return total.Int()
+}
+
+// histogramSimilarity sums value * leftCount * rightCount over values in both lists
+func histogramSimilarity(left, right []bucket) (int, error) {
+	var total checked.Sum
+	i, j := 0, 0
+
+	for i < len(left) && j < len(right) {
+		switch {
+		case left[i].value < right[j].value:
+			i++
+		case left[i].value > right[j].value:
+			j++
+		default:
+			score, ok := checked.Mul(left[i].value, left[i].count)
+			if !ok {
+				return 0, fmt.Errorf("%w: %d appearing %d times", checked.ErrOverflow, left[i].value, left[i].count)
+			}
+			total.AddProduct(score, right[j].count)
+			i++
+			j++
+		}
+	}
+
+	return total.Int()
+}
+
+// radixSort sorts values in place with an LSD radix sort over 16-bit digits.
+// Flipping the sign bit makes negative numbers order correctly as unsigned keys.
+func radixSort(values []int) {
+	const bits = 16
+	const mask = 1<<bits - 1
+
+	buffer := make([]int, len(values))
+	src, dst := values, buffer
+	for shift := 0; shift < 64; shift += bits {
+		var counts [1 << bits]int
+		for _, v := range src {
+			counts[(uint64(v)^(1<<63))>>shift&mask]++
+		}
+
+		offset := 0
+		for i, count := range counts {
+			counts[i] = offset
+			offset += count
+		}
+
+		for _, v := range src {
+			digit := (uint64(v) ^ (1 << 63)) >> shift & mask
+			dst[counts[digit]] = v
+			counts[digit]++
+		}
+		src, dst = dst, src
+	}
+	// Four passes leave the sorted result back in values
+}
+
+// splitFields returns the first two whitespace-separated fields of line and the
+// total number of fields, without allocating
+func splitFields(line []byte) ([]byte, []byte, int) {
+	var fields [2][]byte
+	count := 0
+
+	for i := 0; i < len(line); {
+		for i < len(line) && isSpace(line[i]) {
+			i++
+		}
+		if i == len(line) {
+			break
+		}
+		start := i
+		for i < len(line) && !isSpace(line[i]) {
+			i++
+		}
+		if count < len(fields) {
+			fields[count] = line[start:i]
+		}
+		count++
+	}
+
+	return fields[0], fields[1], count
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\v' || c == '\f'
+}
+
+// parseInt is strconv.Atoi for a byte slice, without converting it to a string
+func parseInt(b []byte) (int, error) {
+	negative := false
+	if len(b) > 0 && (b[0] == '-' || b[0] == '+') {
+		negative = b[0] == '-'
+		b = b[1:]
+	}
+	if len(b) == 0 {
+		return 0, fmt.Errorf("empty number")
+	}
+
+	var n uint64
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("invalid digit %q", c)
+		}
+		if n > (math.MaxInt64+1)/10 {
+			return 0, fmt.Errorf("number out of range")
+		}
+		n = n*10 + uint64(c-'0')
+		if n > math.MaxInt64+1 {
+			return 0, fmt.Errorf("number out of range")
+		}
+	}
+
+	if negative {
+		return int(-n), nil
+	}
+	if n > math.MaxInt64 {
+		return 0, fmt.Errorf("number out of range")
+	}
+	return int(n), nil
+}
@@ -0,0 +1,143 @@
+package day01
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"advent-of-code-2024/internal/gen"
+)
+
+func TestSolveStream(t *testing.T) {
+	file, err := os.Open("example-input.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	result, err := SolveStream(file)
+	if err != nil {
+		t.Fatalf("SolveStream() error = %v", err)
+	}
+
+	expected := StreamResult{Pairs: 6, Distance: 11, Similarity: 31}
+	if result != expected {
+		t.Errorf("SolveStream() = %+v, expected %+v", result, expected)
+	}
+}
+
+func TestSolveStreamMatchesSolvers(t *testing.T) {
+	inputs := map[string]string{
+		"sparse":   "1 -5000000\n9000000 3\n-7 -7\n4000000000 -7\n",
+		"negative": "-3 -4\n-1 2\n-3 -3\n",
+		"blank":    "\n3   4\n\n4   3\n",
+		"extremes": "9223372036854775807 9223372036854775807\n-9223372036854775808 -9223372036854775808\n",
+	}
+	for _, seed := range []int64{1, 2, 3} {
+		input, err := gen.String(1, 500, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs[fmt.Sprintf("generated seed %d", seed)] = input
+	}
+
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			path := t.TempDir() + "/input.txt"
+			if err := os.WriteFile(path, []byte(input), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			part1, err := SolvePart1(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			part2, err := SolvePart2(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := SolveStream(strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("SolveStream() error = %v", err)
+			}
+			if result.Distance != part1 || result.Similarity != part2 {
+				t.Errorf("SolveStream() = %+v, expected distance %d and similarity %d", result, part1, part2)
+			}
+		})
+	}
+}
+
+func TestSolveStreamErrors(t *testing.T) {
+	tests := []string{
+		"1 2 3\n",
+		"1\n",
+		"a 2\n",
+		"1 b\n",
+		"1 99999999999999999999\n",
+	}
+
+	for _, input := range tests {
+		if _, err := SolveStream(strings.NewReader(input)); err == nil {
+			t.Errorf("SolveStream(%q) expected error", input)
+		}
+	}
+}
+
+func TestHistogramSwitchesToSparse(t *testing.T) {
+	var h histogram
+	for _, v := range []int{5, 3, 5, maxDenseRange * 2, -1} {
+		h.add(v)
+	}
+
+	if h.sparse == nil {
+		t.Error("Expected histogram to switch to sparse counts")
+	}
+
+	expected := []bucket{{-1, 1}, {3, 1}, {5, 2}, {maxDenseRange * 2, 1}}
+	if got := h.buckets(); !slices.Equal(got, expected) {
+		t.Errorf("buckets() = %v, expected %v", got, expected)
+	}
+}
+
+func TestRadixSort(t *testing.T) {
+	values := []int{42, -1, math.MaxInt, 0, math.MinInt, 7, -70000, 70000, 42}
+	expected := slices.Clone(values)
+	slices.Sort(expected)
+
+	radixSort(values)
+	if !slices.Equal(values, expected) {
+		t.Errorf("radixSort() = %v, expected %v", values, expected)
+	}
+}
+
+func TestParseInt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+		wantErr  bool
+	}{
+		{"0", 0, false},
+		{"+12", 12, false},
+		{"-12", -12, false},
+		{"9223372036854775807", math.MaxInt64, false},
+		{"-9223372036854775808", math.MinInt64, false},
+		{"9223372036854775808", 0, true},
+		{"-", 0, true},
+		{"1x", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseInt([]byte(tt.input))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseInt(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("parseInt(%q) = %d, expected %d", tt.input, got, tt.expected)
+		}
+	}
+}
@@ -33,9 +33,14 @@ package day02
 
 import (
 	"bufio"
+	"fmt"
+	"io"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
+
+	"advent-of-code-2024/internal/trace"
 )
 
 // Report represents a single report containing levels
@@ -51,8 +56,13 @@ func parseInput(filename string) ([]Report, error) {
 	}
 	defer file.Close()
 
+	return parseReader(file)
+}
+
+// parseReader converts each non-empty line read from r to a Report
+func parseReader(r io.Reader) ([]Report, error) {
 	var reports []Report
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(r)
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -81,20 +91,85 @@ func parseInput(filename string) ([]Report, error) {
 	return reports, nil
 }
 
+// SafetyPolicy sets the rules a report has to follow to count as safe
+type SafetyPolicy struct {
+	MinStep          int  // smallest allowed change between adjacent levels, at least 1
+	MaxStep          int  // largest allowed change between adjacent levels
+	AllowPlateaus    bool // adjacent levels may also be equal
+	RequireDirection bool // every change must go the same way
+	DampenerBudget   int  // levels that may be removed from each report
+}
+
+// PuzzlePolicy returns the puzzle's rules: levels change by 1-3 at every step,
+// always in the same direction, and nothing may be removed
+func PuzzlePolicy() SafetyPolicy {
+	return SafetyPolicy{MinStep: 1, MaxStep: 3, RequireDirection: true}
+}
+
+// Validate checks that the policy's bounds make sense
+func (p SafetyPolicy) Validate() error {
+	if p.MinStep < 1 {
+		return fmt.Errorf("minimum step must be at least 1 (allow plateaus for equal levels), got %d", p.MinStep)
+	}
+	if p.MaxStep < p.MinStep {
+		return fmt.Errorf("maximum step %d is below minimum step %d", p.MaxStep, p.MinStep)
+	}
+	if p.DampenerBudget < 0 {
+		return fmt.Errorf("dampener budget must not be negative, got %d", p.DampenerBudget)
+	}
+	return nil
+}
+
+// validStep checks a change from a to b. direction is 1 or -1 when the policy
+// requires one, and 0 otherwise.
+func (p SafetyPolicy) validStep(a, b, direction int) bool {
+	diff := b - a
+	if diff == 0 {
+		return p.AllowPlateaus
+	}
+	if direction == 0 {
+		diff = max(diff, -diff)
+	} else {
+		diff *= direction
+	}
+	return diff >= p.MinStep && diff <= p.MaxStep
+}
+
+// directions lists the directions a safe report may take under the policy
+func (p SafetyPolicy) directions() []int {
+	if p.RequireDirection {
+		return []int{1, -1}
+	}
+	return []int{0}
+}
+
 // IsSafe checks if a report is safe according to the rules:
 // 1. All levels must be either increasing or decreasing
 // 2. Adjacent levels must differ by at least 1 and at most 3
 func (r Report) IsSafe() bool {
+	return r.follows(PuzzlePolicy())
+}
+
+// follows checks the report against policy's rules without removing any levels
+func (r Report) follows(policy SafetyPolicy) bool {
 	if len(r.Levels) < 2 {
 		return true
 	}
 
-	return r.isMonotonic() && r.hasValidDifferences()
+	return r.isMonotonic(policy) && r.hasValidDifferences(policy)
 }
 
-// isMonotonic checks if the sequence is either all increasing or all decreasing
-func (r Report) isMonotonic() bool {
-	if len(r.Levels) < 2 {
+// IsSafeUnder checks if a report is safe under policy, removing up to
+// policy.DampenerBudget levels if that helps
+func (r Report) IsSafeUnder(policy SafetyPolicy) bool {
+	_, safe := r.RemovalsUnder(policy)
+	return safe
+}
+
+// isMonotonic checks if the sequence is either all increasing or all decreasing,
+// ignoring plateaus where the policy allows them
+func (r Report) isMonotonic(policy SafetyPolicy) bool {
+	if len(r.Levels) < 2 || !policy.RequireDirection {
 		return true
 	}
 
@@ -106,7 +181,7 @@ func (r Report) isMonotonic() bool {
 			decreasing = false
 		} else if r.Levels[i] < r.Levels[i-1] {
 			increasing = false
-		} else {
+		} else if !policy.AllowPlateaus {
 			// Equal values mean neither increasing nor decreasing
 			return false
 		}
@@ -115,67 +190,195 @@ func (r Report) isMonotonic() bool {
 	return increasing || decreasing
 }
 
-// hasValidDifferences checks if adjacent levels differ by 1-3
-func (r Report) hasValidDifferences() bool {
+// hasValidDifferences checks if adjacent levels differ by the policy's step bounds
+func (r Report) hasValidDifferences(policy SafetyPolicy) bool {
 	for i := 1; i < len(r.Levels); i++ {
-		diff := r.Levels[i] - r.Levels[i-1]
-		if diff < 0 {
-			diff = -diff
-		}
-		if diff < 1 || diff > 3 {
+		if !policy.validStep(r.Levels[i-1], r.Levels[i], 0) {
 			return false
 		}
 	}
 	return true
 }
 
+// unsafeReason describes the first pair of adjacent levels that breaks the policy's
+// rules, or returns an empty string if the report is safe
+func (r Report) unsafeReason(policy SafetyPolicy) string {
+	i, kind, diff := r.firstViolation(policy)
+	if i < 0 {
+		return ""
+	}
+
+	step := fmt.Sprintf("%d -> %d at index %d", r.Levels[i-1], r.Levels[i], i)
+	switch kind {
+	case Plateau:
+		return "no change " + step
+	case DirectionChange:
+		return "direction changes " + step
+	case StepTooLarge:
+		return fmt.Sprintf("step of %d is too large %s", diff, step)
+	default:
+		return fmt.Sprintf("step of %d is too small %s", diff, step)
+	}
+}
+
 // CountSafeReports counts how many reports in the slice are safe
 func CountSafeReports(reports []Report) int {
-	count := 0
-	for _, report := range reports {
-		if report.IsSafe() {
-			count++
-		}
-	}
-	return count
+	return CountSafeReportsUnder(reports, PuzzlePolicy())
 }
 
 // IsSafeWithDampener checks if a report is safe with Problem Dampener
 // (safe as-is OR safe after removing exactly one level)
 func (r Report) IsSafeWithDampener() bool {
-	// First check if already safe
-	if r.IsSafe() {
-		return true
+	_, safe := r.dampenerRemoval()
+	return safe
+}
+
+// dampenerRemoval returns the index of the level whose removal makes the report
+// safe, -1 if it is already safe, and false if no single removal helps
+func (r Report) dampenerRemoval() (int, bool) {
+	removed, safe := r.IsSafeWithTolerance(1)
+	if !safe || len(removed) == 0 {
+		return -1, safe
 	}
+	return removed[0], true
+}
+
+// IsSafeWithTolerance reports whether removing at most k levels makes the report
+// safe, and returns the indices of the fewest levels to remove (none if it is
+// already safe). Among equally short answers it picks the one that removes the
+// earliest levels.
+func (r Report) IsSafeWithTolerance(k int) ([]int, bool) {
+	policy := PuzzlePolicy()
+	policy.DampenerBudget = max(k, 0)
+	return r.RemovalsUnder(policy)
+}
 
-	// Try removing each level one at a time
-	for i := 0; i < len(r.Levels); i++ {
-		// Create new slice without element at index i
-		dampened := make([]int, 0, len(r.Levels)-1)
-		dampened = append(dampened, r.Levels[:i]...)
-		dampened = append(dampened, r.Levels[i+1:]...)
+// RemovalsUnder is IsSafeWithTolerance for any policy, with policy.DampenerBudget
+// as the number of levels that may be removed
+func (r Report) RemovalsUnder(policy SafetyPolicy) ([]int, bool) {
+	var best []int
+	found := false
 
-		// Check if the dampened report is safe
-		dampenedReport := Report{Levels: dampened}
-		if dampenedReport.IsSafe() {
-			return true
+	for _, direction := range policy.directions() {
+		removed, ok := r.removalsFor(policy, direction)
+		if !ok {
+			continue
+		}
+		if !found || len(removed) < len(best) || len(removed) == len(best) && slices.Compare(removed, best) < 0 {
+			best, found = removed, true
+		}
+	}
+
+	return best, found
+}
+
+// removalsFor finds the fewest removals, at most the policy's budget k, that leave
+// every step valid in direction. Working backwards, cost[i] is the fewest removals
+// after i when level i is kept and next[i] is the next kept level (len(r.Levels)
+// if none are). Only the k+1 levels after i can be next without exceeding k, so
+// this is O(n·k).
+func (r Report) removalsFor(policy SafetyPolicy, direction int) ([]int, bool) {
+	n := len(r.Levels)
+	if n < 2 {
+		return nil, true
+	}
+	k := policy.DampenerBudget
+
+	cost := make([]int, n)
+	next := make([]int, n)
+	for i := n - 1; i >= 0; i-- {
+		// Removing everything after i is always possible. Later candidates are tried
+		// first so that ties keep the one removing the earliest levels.
+		cost[i], next[i] = n-1-i, n
+		for j := min(i+k+1, n-1); j > i; j-- {
+			if !policy.validStep(r.Levels[i], r.Levels[j], direction) {
+				continue
+			}
+			if c := j - i - 1 + cost[j]; c < cost[i] {
+				cost[i], next[i] = c, j
+			}
+		}
+	}
+
+	// Removing the first start levels, then keeping level start
+	start := min(k, n-1)
+	for s := start - 1; s >= 0; s-- {
+		if s+cost[s] < start+cost[start] {
+			start = s
+		}
+	}
+	if start+cost[start] > k {
+		return nil, false
+	}
+
+	var removed []int
+	for i := 0; i < start; i++ {
+		removed = append(removed, i)
+	}
+	for i := start; i < n; i = next[i] {
+		for j := i + 1; j < next[i]; j++ {
+			removed = append(removed, j)
 		}
 	}
 
-	return false
+	return removed, true
 }
 
 // CountSafeReportsWithDampener counts how many reports are safe with Problem Dampener
 func CountSafeReportsWithDampener(reports []Report) int {
+	return CountSafeReportsWithTolerance(reports, 1)
+}
+
+// CountSafeReportsWithTolerance counts how many reports are safe after removing at
+// most k levels from each
+func CountSafeReportsWithTolerance(reports []Report, k int) int {
+	policy := PuzzlePolicy()
+	policy.DampenerBudget = max(k, 0)
+	return CountSafeReportsUnder(reports, policy)
+}
+
+// CountSafeReportsUnder counts how many reports are safe under policy
+func CountSafeReportsUnder(reports []Report, policy SafetyPolicy) int {
 	count := 0
-	for _, report := range reports {
-		if report.IsSafeWithDampener() {
+	for i, report := range reports {
+		removed, safe := report.RemovalsUnder(policy)
+		if safe {
 			count++
 		}
+
+		if trace.Enabled() {
+			traceReport(i, report, policy, removed, safe)
+		}
 	}
 	return count
 }
 
+// traceReport explains why report i is safe or unsafe under policy
+func traceReport(i int, report Report, policy SafetyPolicy, removed []int, safe bool) {
+	reason := report.unsafeReason(policy)
+	if policy.DampenerBudget == 0 {
+		message := fmt.Sprintf("report %d %v is safe", i+1, report.Levels)
+		if !safe {
+			message = fmt.Sprintf("report %d %v is unsafe: %s", i+1, report.Levels, reason)
+		}
+		trace.Emit("report", message, map[string]any{"index": i, "levels": report.Levels, "safe": safe, "reason": reason})
+		return
+	}
+
+	var message string
+	switch {
+	case safe && len(removed) == 0:
+		message = fmt.Sprintf("report %d %v is safe without the dampener", i+1, report.Levels)
+	case safe && len(removed) == 1:
+		message = fmt.Sprintf("report %d %v is safe after removing level %d at index %d", i+1, report.Levels, report.Levels[removed[0]], removed[0])
+	case safe:
+		message = fmt.Sprintf("report %d %v is safe after removing levels at indices %v", i+1, report.Levels, removed)
+	default:
+		message = fmt.Sprintf("report %d %v is unsafe even with the dampener: %s", i+1, report.Levels, reason)
+	}
+	trace.Emit("report", message, map[string]any{"index": i, "levels": report.Levels, "safe": safe, "removed": removed})
+}
+
 // SolvePart1 reads input file and returns the count of safe reports
 func SolvePart1(filename string) (int, error) {
 	reports, err := parseInput(filename)
@@ -193,3 +396,17 @@ func SolvePart2(filename string) (int, error) {
 	}
 	return CountSafeReportsWithDampener(reports), nil
 }
+
+// SolveWithPolicy reads input file and returns the count of reports that are safe
+// under policy
+func SolveWithPolicy(filename string, policy SafetyPolicy) (int, error) {
+	if err := policy.Validate(); err != nil {
+		return 0, err
+	}
+
+	reports, err := parseInput(filename)
+	if err != nil {
+		return 0, err
+	}
+	return CountSafeReportsUnder(reports, policy), nil
+}
@@ -0,0 +1,53 @@
+package day02
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func FuzzParseInput(f *testing.F) {
+	example, err := os.ReadFile("example-input.txt")
+	if err != nil {
+		f.Fatalf("failed to read example input: %v", err)
+	}
+	f.Add(string(example))
+	f.Add("")
+	f.Add("1 x 3")
+	f.Add("\n\n4\n")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		reports, err := parseReader(strings.NewReader(data))
+		if err != nil {
+			return
+		}
+		for i, report := range reports {
+			if len(report.Levels) == 0 {
+				t.Fatalf("report %d has no levels", i)
+			}
+		}
+	})
+}
+
+func FuzzIsSafe(f *testing.F) {
+	example, err := os.ReadFile("example-input.txt")
+	if err != nil {
+		f.Fatalf("failed to read example input: %v", err)
+	}
+	f.Add(string(example))
+	f.Add("1")
+	f.Add("3 3 3 3")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		reports, err := parseReader(strings.NewReader(data))
+		if err != nil {
+			return
+		}
+		for _, report := range reports {
+			// A report that is safe as-is must also be safe with the dampener
+			if report.IsSafe() && !report.IsSafeWithDampener() {
+				t.Fatalf("report %v is safe but not safe with dampener", report.Levels)
+			}
+		}
+	})
+}
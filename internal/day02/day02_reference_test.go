@@ -0,0 +1,91 @@
+package day02
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"advent-of-code-2024/internal/difftest"
+)
+
+func referenceReports(input string) ([][]int, error) {
+	var reports [][]int
+	for _, line := range strings.Split(input, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		levels := make([]int, len(fields))
+		for i, field := range fields {
+			level, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, err
+			}
+			levels[i] = level
+		}
+		reports = append(reports, levels)
+	}
+	return reports, nil
+}
+
+// referenceSafe checks every step lies in 1..3, or every step lies in -3..-1
+func referenceSafe(levels []int) bool {
+	allUp, allDown := true, true
+	for i := 1; i < len(levels); i++ {
+		step := levels[i] - levels[i-1]
+		if step < 1 || step > 3 {
+			allUp = false
+		}
+		if step > -1 || step < -3 {
+			allDown = false
+		}
+	}
+	return allUp || allDown
+}
+
+func referencePart1(input string) (int, error) {
+	reports, err := referenceReports(input)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, levels := range reports {
+		if referenceSafe(levels) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// referencePart2 tries the report as-is and with every single level removed
+func referencePart2(input string) (int, error) {
+	reports, err := referenceReports(input)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, levels := range reports {
+		safe := referenceSafe(levels)
+		for skip := 0; skip < len(levels) && !safe; skip++ {
+			var remaining []int
+			for i, level := range levels {
+				if i != skip {
+					remaining = append(remaining, level)
+				}
+			}
+			safe = referenceSafe(remaining)
+		}
+		if safe {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func TestDifferential(t *testing.T) {
+	difftest.Run(t, 2, []int{1, 10, 200},
+		difftest.Part{Solve: SolvePart1, Reference: referencePart1},
+		difftest.Part{Solve: SolvePart2, Reference: referencePart2})
+}
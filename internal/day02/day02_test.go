@@ -1,8 +1,12 @@
 package day02
 
 import (
+	"math/rand"
 	"os"
+	"slices"
 	"testing"
+
+	"advent-of-code-2024/internal/trace"
 )
 
 func TestParseInput(t *testing.T) {
@@ -228,3 +232,241 @@ func TestSolvePart2(t *testing.T) {
 		t.Errorf("SolvePart2() = %d, want %d", result, expected)
 	}
 }
+
+func TestUnsafeReason(t *testing.T) {
+	tests := []struct {
+		levels   []int
+		expected string
+	}{
+		{[]int{7, 6, 4, 2, 1}, ""},
+		{[]int{1, 2, 7, 8, 9}, "step of 5 is too large 2 -> 7 at index 2"},
+		{[]int{9, 7, 6, 2, 1}, "step of -4 is too large 6 -> 2 at index 3"},
+		{[]int{1, 3, 2, 4, 5}, "direction changes 3 -> 2 at index 2"},
+		{[]int{8, 6, 4, 4, 1}, "no change 4 -> 4 at index 3"},
+	}
+
+	for _, tt := range tests {
+		result := Report{Levels: tt.levels}.unsafeReason(PuzzlePolicy())
+		if result != tt.expected {
+			t.Errorf("unsafeReason(%v) = %q, want %q", tt.levels, result, tt.expected)
+		}
+	}
+}
+
+func TestTraceEvents(t *testing.T) {
+	var events []trace.Event
+	trace.SetHook(func(e trace.Event) { events = append(events, e) })
+	defer trace.SetHook(nil)
+
+	if _, err := SolvePart2("example-input.txt"); err != nil {
+		t.Fatalf("SolvePart2 failed: %v", err)
+	}
+
+	if len(events) != 6 {
+		t.Fatalf("Expected 6 report events, got %d", len(events))
+	}
+
+	expected := "report 4 [1 3 2 4 5] is safe after removing level 3 at index 1"
+	if events[3].Message != expected {
+		t.Errorf("events[3].Message = %q, want %q", events[3].Message, expected)
+	}
+	if events[1].Fields["safe"] != false {
+		t.Errorf("events[1] should report an unsafe report, got %+v", events[1])
+	}
+}
+
+func TestIsSafeWithTolerance(t *testing.T) {
+	tests := []struct {
+		levels   []int
+		k        int
+		expected []int
+		safe     bool
+	}{
+		{[]int{7, 6, 4, 2, 1}, 0, nil, true},
+		{[]int{1, 3, 2, 4, 5}, 0, nil, false},
+		{[]int{1, 3, 2, 4, 5}, 1, []int{1}, true},
+		{[]int{8, 6, 4, 4, 1}, 1, []int{2}, true},
+		{[]int{1, 2, 7, 8, 9}, 1, nil, false},
+		{[]int{1, 2, 7, 8, 9}, 2, []int{0, 1}, true},
+		{[]int{1, 9, 9, 2, 3}, 2, []int{1, 2}, true},
+		{[]int{5, 1, 2, 3, 4, 9}, 2, []int{0, 5}, true},
+		{[]int{4}, 0, nil, true},
+		{[]int{4, 4}, 1, []int{0}, true},
+		{[]int{1, 2, 3}, -1, nil, true},
+	}
+
+	for _, tt := range tests {
+		removed, safe := Report{Levels: tt.levels}.IsSafeWithTolerance(tt.k)
+		if safe != tt.safe || !slices.Equal(removed, tt.expected) {
+			t.Errorf("IsSafeWithTolerance(%v, %d) = %v, %v, want %v, %v", tt.levels, tt.k, removed, safe, tt.expected, tt.safe)
+		}
+	}
+}
+
+// bruteForceTolerance tries every subset of levels to remove, smallest first
+func bruteForceTolerance(levels []int, k int) ([]int, bool) {
+	policy := PuzzlePolicy()
+	policy.DampenerBudget = k
+	return bruteForceRemovals(levels, policy)
+}
+
+// bruteForceRemovals is bruteForceTolerance under any policy
+func bruteForceRemovals(levels []int, policy SafetyPolicy) ([]int, bool) {
+	k := policy.DampenerBudget
+	n := len(levels)
+	var best []int
+	found := false
+	for mask := 0; mask < 1<<n; mask++ {
+		var removed, kept []int
+		for i, level := range levels {
+			if mask&(1<<i) != 0 {
+				removed = append(removed, i)
+			} else {
+				kept = append(kept, level)
+			}
+		}
+		if len(removed) > k || !(Report{Levels: kept}).follows(policy) {
+			continue
+		}
+		if !found || len(removed) < len(best) || len(removed) == len(best) && slices.Compare(removed, best) < 0 {
+			best, found = removed, true
+		}
+	}
+	return best, found
+}
+
+func TestIsSafeWithToleranceMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 5000; trial++ {
+		levels := make([]int, rng.Intn(10))
+		for i := range levels {
+			levels[i] = rng.Intn(12)
+		}
+		k := rng.Intn(4)
+
+		removed, safe := Report{Levels: levels}.IsSafeWithTolerance(k)
+		expected, expectedSafe := bruteForceTolerance(levels, k)
+		if safe != expectedSafe || !slices.Equal(removed, expected) {
+			t.Fatalf("IsSafeWithTolerance(%v, %d) = %v, %v, want %v, %v", levels, k, removed, safe, expected, expectedSafe)
+		}
+	}
+}
+
+func TestCountSafeReportsWithTolerance(t *testing.T) {
+	reports, err := parseInput("example-input.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// k = 2 also rescues 1 2 7 8 9 (drop 1 and 2) and 9 7 6 2 1 (drop 2 and 1)
+	for k, expected := range []int{2, 4, 6} {
+		if result := CountSafeReportsWithTolerance(reports, k); result != expected {
+			t.Errorf("CountSafeReportsWithTolerance(k=%d) = %d, want %d", k, result, expected)
+		}
+	}
+}
+
+func TestSafetyPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   SafetyPolicy
+		levels   []int
+		expected bool
+	}{
+		{"puzzle rules", PuzzlePolicy(), []int{1, 3, 6, 7, 9}, true},
+		{"wider steps", SafetyPolicy{MinStep: 1, MaxStep: 5, RequireDirection: true}, []int{1, 2, 7, 8, 9}, true},
+		{"minimum step", SafetyPolicy{MinStep: 2, MaxStep: 3, RequireDirection: true}, []int{1, 3, 6, 7, 9}, false},
+		{"plateau rejected", PuzzlePolicy(), []int{8, 6, 4, 4, 1}, false},
+		{"plateau allowed", SafetyPolicy{MinStep: 1, MaxStep: 3, AllowPlateaus: true, RequireDirection: true}, []int{8, 6, 4, 4, 1}, true},
+		{"plateau still needs one direction", SafetyPolicy{MinStep: 1, MaxStep: 3, AllowPlateaus: true, RequireDirection: true}, []int{1, 3, 3, 2}, false},
+		{"direction optional", SafetyPolicy{MinStep: 1, MaxStep: 3}, []int{1, 3, 2, 4, 5}, true},
+		{"dampener budget", SafetyPolicy{MinStep: 1, MaxStep: 3, RequireDirection: true, DampenerBudget: 2}, []int{1, 2, 7, 8, 9}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := (Report{Levels: tt.levels}).IsSafeUnder(tt.policy); result != tt.expected {
+				t.Errorf("IsSafeUnder(%+v) = %v, want %v", tt.policy, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSafetyPolicyValidate(t *testing.T) {
+	tests := []struct {
+		policy  SafetyPolicy
+		wantErr bool
+	}{
+		{PuzzlePolicy(), false},
+		{SafetyPolicy{MinStep: 2, MaxStep: 2}, false},
+		{SafetyPolicy{MinStep: 0, MaxStep: 3, AllowPlateaus: true}, true},
+		{SafetyPolicy{MinStep: 4, MaxStep: 3}, true},
+		{SafetyPolicy{MinStep: 1, MaxStep: 3, DampenerBudget: -1}, true},
+	}
+
+	for _, tt := range tests {
+		if err := tt.policy.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%+v) error = %v, wantErr %v", tt.policy, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRemovalsUnderMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for trial := 0; trial < 5000; trial++ {
+		levels := make([]int, rng.Intn(9))
+		for i := range levels {
+			levels[i] = rng.Intn(10)
+		}
+		minStep := 1 + rng.Intn(2)
+		policy := SafetyPolicy{
+			MinStep:          minStep,
+			MaxStep:          minStep + rng.Intn(4),
+			AllowPlateaus:    rng.Intn(2) == 0,
+			RequireDirection: rng.Intn(2) == 0,
+			DampenerBudget:   rng.Intn(3),
+		}
+
+		removed, safe := Report{Levels: levels}.RemovalsUnder(policy)
+		expected, expectedSafe := bruteForceRemovals(levels, policy)
+		if safe != expectedSafe || !slices.Equal(removed, expected) {
+			t.Fatalf("RemovalsUnder(%v, %+v) = %v, %v, want %v, %v", levels, policy, removed, safe, expected, expectedSafe)
+		}
+	}
+}
+
+func TestSolveWithPolicy(t *testing.T) {
+	policy := PuzzlePolicy()
+	if result, err := SolveWithPolicy("example-input.txt", policy); err != nil || result != 2 {
+		t.Errorf("SolveWithPolicy(puzzle) = %d, %v, want 2", result, err)
+	}
+
+	policy.DampenerBudget = 1
+	if result, err := SolveWithPolicy("example-input.txt", policy); err != nil || result != 4 {
+		t.Errorf("SolveWithPolicy(dampener) = %d, %v, want 4", result, err)
+	}
+
+	policy.MinStep = 0
+	if _, err := SolveWithPolicy("example-input.txt", policy); err == nil {
+		t.Error("SolveWithPolicy should reject an invalid policy")
+	}
+}
+
+func TestUnsafeReasonUnderPolicy(t *testing.T) {
+	policy := SafetyPolicy{MinStep: 2, MaxStep: 3, AllowPlateaus: true, RequireDirection: true}
+	tests := []struct {
+		levels   []int
+		expected string
+	}{
+		{[]int{8, 6, 4, 4, 1}, ""},
+		{[]int{1, 3, 6, 7, 9}, "step of 1 is too small 6 -> 7 at index 3"},
+		{[]int{1, 3, 3, 1}, "direction changes 3 -> 1 at index 3"},
+	}
+
+	for _, tt := range tests {
+		result := Report{Levels: tt.levels}.unsafeReason(policy)
+		if result != tt.expected {
+			t.Errorf("unsafeReason(%v) = %q, want %q", tt.levels, result, tt.expected)
+		}
+	}
+}
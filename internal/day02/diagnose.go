@@ -0,0 +1,160 @@
+package day02
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ViolationKind names the rule that a pair of adjacent levels breaks
+type ViolationKind string
+
+const (
+	DirectionChange ViolationKind = "direction change"
+	Plateau         ViolationKind = "plateau"
+	StepTooLarge    ViolationKind = "step too large"
+	StepTooSmall    ViolationKind = "step too small"
+)
+
+// violationKinds lists every kind in the order reports show them
+var violationKinds = []ViolationKind{DirectionChange, Plateau, StepTooLarge, StepTooSmall}
+
+// Diagnosis explains why a report is unsafe
+type Diagnosis struct {
+	Safe bool `json:"safe"`
+	// Index is the level that breaks the rules, compared with the one before it,
+	// or -1 if the report is safe
+	Index int           `json:"index"`
+	Kind  ViolationKind `json:"kind,omitempty"`
+	Delta int           `json:"delta"` // Levels[Index] - Levels[Index-1]
+	// Repair is the index of a single level whose removal makes the report safe,
+	// or -1 if the report is safe or no single removal helps
+	Repair     int  `json:"repair"`
+	Repairable bool `json:"repairable"`
+}
+
+// Diagnose finds the first violation of the puzzle's rules and the single
+// removal, if any, that repairs it
+func (r Report) Diagnose() Diagnosis {
+	return r.DiagnoseUnder(PuzzlePolicy())
+}
+
+// DiagnoseUnder is Diagnose for any policy. Repair always considers a single
+// removal, whatever the policy's dampener budget.
+func (r Report) DiagnoseUnder(policy SafetyPolicy) Diagnosis {
+	index, kind, delta := r.firstViolation(policy)
+	if index < 0 {
+		return Diagnosis{Safe: true, Index: -1, Repair: -1}
+	}
+
+	diagnosis := Diagnosis{Index: index, Kind: kind, Delta: delta, Repair: -1}
+
+	policy.DampenerBudget = 1
+	if removed, ok := r.RemovalsUnder(policy); ok {
+		diagnosis.Repair, diagnosis.Repairable = removed[0], true
+	}
+	return diagnosis
+}
+
+// firstViolation finds the first pair of adjacent levels that breaks the policy's
+// rules, returning the index of the second level, or -1 if there is none
+func (r Report) firstViolation(policy SafetyPolicy) (int, ViolationKind, int) {
+	direction := 0
+	for i := 1; i < len(r.Levels); i++ {
+		diff := r.Levels[i] - r.Levels[i-1]
+
+		switch {
+		case diff == 0 && policy.AllowPlateaus:
+			continue
+		case diff == 0:
+			return i, Plateau, diff
+		case policy.RequireDirection && direction != 0 && (diff > 0) != (direction > 0):
+			return i, DirectionChange, diff
+		case diff > policy.MaxStep || diff < -policy.MaxStep:
+			return i, StepTooLarge, diff
+		case diff < policy.MinStep && diff > -policy.MinStep:
+			return i, StepTooSmall, diff
+		}
+
+		direction = diff
+	}
+	return -1, "", 0
+}
+
+// DiagnosedReport is an unsafe report together with its diagnosis
+type DiagnosedReport struct {
+	Line      int       `json:"line"` // 1-based position among the reports
+	Levels    []int     `json:"levels"`
+	Diagnosis Diagnosis `json:"diagnosis"`
+}
+
+// DiagnosisSummary counts how reports break the rules
+type DiagnosisSummary struct {
+	Reports      int                   `json:"reports"`
+	Safe         int                   `json:"safe"`
+	Repairable   int                   `json:"repairable"`
+	Violations   map[ViolationKind]int `json:"violations"`
+	Unrepairable []DiagnosedReport     `json:"unrepairable"`
+}
+
+// DiagnoseFile reads reports from filename and summarises their diagnoses under policy
+func DiagnoseFile(filename string, policy SafetyPolicy) (*DiagnosisSummary, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	reports, err := parseInput(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return SummariseDiagnoses(reports, policy), nil
+}
+
+// SummariseDiagnoses counts the first violation of each unsafe report by kind and
+// collects the reports that no single removal repairs
+func SummariseDiagnoses(reports []Report, policy SafetyPolicy) *DiagnosisSummary {
+	summary := &DiagnosisSummary{
+		Reports:      len(reports),
+		Violations:   make(map[ViolationKind]int),
+		Unrepairable: []DiagnosedReport{},
+	}
+
+	for i, report := range reports {
+		diagnosis := report.DiagnoseUnder(policy)
+		switch {
+		case diagnosis.Safe:
+			summary.Safe++
+			continue
+		case diagnosis.Repairable:
+			summary.Repairable++
+		default:
+			summary.Unrepairable = append(summary.Unrepairable, DiagnosedReport{Line: i + 1, Levels: report.Levels, Diagnosis: diagnosis})
+		}
+		summary.Violations[diagnosis.Kind]++
+	}
+
+	return summary
+}
+
+// WriteTable writes the summary as plain text
+func (s *DiagnosisSummary) WriteTable(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%d reports: %d safe, %d repairable by one removal, %d unrepairable\n",
+		s.Reports, s.Safe, s.Repairable, len(s.Unrepairable))
+
+	b.WriteString("\nFirst violation by kind\n")
+	for _, kind := range violationKinds {
+		fmt.Fprintf(&b, "  %-16s %6d\n", kind, s.Violations[kind])
+	}
+
+	fmt.Fprintf(&b, "\nUnrepairable reports (%d)\n", len(s.Unrepairable))
+	for _, report := range s.Unrepairable {
+		d := report.Diagnosis
+		fmt.Fprintf(&b, "  line %d %v: %s of %d at index %d\n", report.Line, report.Levels, d.Kind, d.Delta, d.Index)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
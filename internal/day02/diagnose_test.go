@@ -0,0 +1,110 @@
+package day02
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDiagnose(t *testing.T) {
+	tests := []struct {
+		levels   []int
+		expected Diagnosis
+	}{
+		{[]int{7, 6, 4, 2, 1}, Diagnosis{Safe: true, Index: -1, Repair: -1}},
+		{[]int{1, 2, 7, 8, 9}, Diagnosis{Index: 2, Kind: StepTooLarge, Delta: 5, Repair: -1}},
+		{[]int{9, 7, 6, 2, 1}, Diagnosis{Index: 3, Kind: StepTooLarge, Delta: -4, Repair: -1}},
+		{[]int{1, 3, 2, 4, 5}, Diagnosis{Index: 2, Kind: DirectionChange, Delta: -1, Repair: 1, Repairable: true}},
+		{[]int{8, 6, 4, 4, 1}, Diagnosis{Index: 3, Kind: Plateau, Delta: 0, Repair: 2, Repairable: true}},
+		{[]int{1, 3, 6, 7, 9}, Diagnosis{Safe: true, Index: -1, Repair: -1}},
+	}
+
+	for _, tt := range tests {
+		result := Report{Levels: tt.levels}.Diagnose()
+		if result != tt.expected {
+			t.Errorf("Diagnose(%v) = %+v, want %+v", tt.levels, result, tt.expected)
+		}
+	}
+}
+
+func TestDiagnoseUnderPolicy(t *testing.T) {
+	// The dampener budget is ignored: repairs are always a single removal
+	policy := SafetyPolicy{MinStep: 2, MaxStep: 3, RequireDirection: true, DampenerBudget: 3}
+
+	result := Report{Levels: []int{1, 3, 4, 6}}.DiagnoseUnder(policy)
+	expected := Diagnosis{Index: 2, Kind: StepTooSmall, Delta: 1, Repair: 1, Repairable: true}
+	if result != expected {
+		t.Errorf("DiagnoseUnder() = %+v, want %+v", result, expected)
+	}
+
+	result = Report{Levels: []int{1, 2, 3, 4}}.DiagnoseUnder(policy)
+	if result.Kind != StepTooSmall || result.Repairable {
+		t.Errorf("DiagnoseUnder() = %+v, want an unrepairable step too small", result)
+	}
+}
+
+func TestDiagnoseAgreesWithIsSafe(t *testing.T) {
+	reports, err := parseInput("puzzle-input.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, report := range reports {
+		diagnosis := report.Diagnose()
+		if diagnosis.Safe != report.IsSafe() {
+			t.Fatalf("Diagnose(%v).Safe = %v, IsSafe() = %v", report.Levels, diagnosis.Safe, report.IsSafe())
+		}
+		if !diagnosis.Safe && diagnosis.Repairable != report.IsSafeWithDampener() {
+			t.Fatalf("Diagnose(%v).Repairable = %v, IsSafeWithDampener() = %v", report.Levels, diagnosis.Repairable, report.IsSafeWithDampener())
+		}
+	}
+}
+
+func TestDiagnoseFile(t *testing.T) {
+	summary, err := DiagnoseFile("example-input.txt", PuzzlePolicy())
+	if err != nil {
+		t.Fatalf("DiagnoseFile failed: %v", err)
+	}
+
+	if summary.Reports != 6 || summary.Safe != 2 || summary.Repairable != 2 {
+		t.Errorf("DiagnoseFile() = %d reports, %d safe, %d repairable, want 6, 2, 2", summary.Reports, summary.Safe, summary.Repairable)
+	}
+
+	expectedViolations := map[ViolationKind]int{StepTooLarge: 2, DirectionChange: 1, Plateau: 1}
+	for _, kind := range violationKinds {
+		if summary.Violations[kind] != expectedViolations[kind] {
+			t.Errorf("Violations[%s] = %d, want %d", kind, summary.Violations[kind], expectedViolations[kind])
+		}
+	}
+
+	if len(summary.Unrepairable) != 2 || summary.Unrepairable[0].Line != 2 || summary.Unrepairable[1].Line != 3 {
+		t.Errorf("Unrepairable = %+v, want lines 2 and 3", summary.Unrepairable)
+	}
+
+	if _, err := DiagnoseFile("example-input.txt", SafetyPolicy{}); err == nil {
+		t.Error("DiagnoseFile should reject an invalid policy")
+	}
+}
+
+func TestDiagnosisSummaryWriteTable(t *testing.T) {
+	summary, err := DiagnoseFile("example-input.txt", PuzzlePolicy())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := summary.WriteTable(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"6 reports: 2 safe, 2 repairable by one removal, 2 unrepairable",
+		"  step too large        2",
+		"  line 2 [1 2 7 8 9]: step too large of 5 at index 2",
+		"  line 3 [9 7 6 2 1]: step too large of -4 at index 3",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("WriteTable() output missing %q:\n%s", want, out.String())
+		}
+	}
+}
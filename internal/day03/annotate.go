@@ -0,0 +1,180 @@
+package day03
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"advent-of-code-2024/internal/checked"
+)
+
+// Annotation is one instruction as the interpreter ran it
+type Annotation struct {
+	Instruction
+	Enabled bool `json:"enabled"` // whether mul instructions count after this one ran
+	Counted bool `json:"counted"` // a mul that added to the total
+	Product int  `json:"product"`
+	Total   int  `json:"total"` // running total after this instruction
+}
+
+// AnnotatedProgram is corrupted memory together with the instructions found in
+// it, as one part of the puzzle interprets them
+type AnnotatedProgram struct {
+	Part         int          `json:"part"`
+	Input        string       `json:"-"`
+	Instructions []Annotation `json:"instructions"`
+	Total        int          `json:"total"`
+}
+
+// AnnotateFile reads corrupted memory from filename and annotates it for part
+func AnnotateFile(filename string, part int) (*AnnotatedProgram, error) {
+	content, err := parseInput(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return AnnotateProgram(content, part)
+}
+
+// AnnotateProgram runs input the way part 1 or part 2 does and records every
+// instruction with its effect. Part 1 has no do() or don't(), so those are not
+// instructions there.
+func AnnotateProgram(input string, part int) (*AnnotatedProgram, error) {
+	set := instructionsFor(part)
+
+	program := &AnnotatedProgram{Part: part, Input: input, Instructions: []Annotation{}}
+	visit := func(instruction Instruction, enabled bool, product any, total *checked.Sum) {
+		annotation := Annotation{Instruction: instruction, Enabled: enabled}
+		if instruction.Type == InstructionTypeMul && enabled {
+			annotation.Counted = true
+			annotation.Product, _ = product.(int)
+		}
+		// In Strict mode an overflow stops the run before visit sees it
+		annotation.Total, _ = total.Int()
+		program.Instructions = append(program.Instructions, annotation)
+	}
+
+	total, err := set.runWith(input, Grammar{}, checked.Strict, visit)
+	if err != nil {
+		return nil, err
+	}
+	if program.Total, err = total.Int(); err != nil {
+		return nil, err
+	}
+	return program, nil
+}
+
+// Classes of annotated text
+const (
+	classCounted  = "counted"  // a mul that added to the total
+	classSkipped  = "skipped"  // a mul ignored while disabled
+	classSwitch   = "switch"   // do() or don't()
+	classDisabled = "disabled" // text between a don't() and the next do()
+	classNote     = "note"     // the running total after an instruction
+)
+
+// ansiStyles are the terminal escape codes for each class
+var ansiStyles = map[string]string{
+	classCounted:  "\033[1;32m",
+	classSkipped:  "\033[2;9;31m",
+	classSwitch:   "\033[1;33m",
+	classDisabled: "\033[2m",
+	classNote:     "\033[36m",
+}
+
+const ansiReset = "\033[0m"
+
+// htmlStyle is the stylesheet WriteHTML embeds for its classes
+const htmlStyle = `<style>
+pre.day03 { white-space: pre-wrap; word-break: break-all; }
+pre.day03 .counted { color: #1a7f37; font-weight: bold; }
+pre.day03 .skipped { color: #cf222e; text-decoration: line-through; }
+pre.day03 .switch { color: #9a6700; font-weight: bold; }
+pre.day03 .disabled { opacity: 0.4; }
+pre.day03 .note { color: #0969da; font-size: smaller; vertical-align: super; }
+</style>
+`
+
+// WriteANSI writes the program with counted instructions highlighted, skipped
+// ones struck through and disabled stretches dimmed, for a terminal
+func (p *AnnotatedProgram) WriteANSI(w io.Writer) error {
+	span := func(class, _, text string) string {
+		return ansiStyles[class] + text + ansiReset
+	}
+
+	var out strings.Builder
+	p.render(&out, span, func(s string) string { return s })
+	fmt.Fprintf(&out, "\n%s\n", p.summary())
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// WriteHTML writes the program as a self-contained HTML fragment, styled like
+// WriteANSI. Each instruction's title gives its byte offset.
+func (p *AnnotatedProgram) WriteHTML(w io.Writer) error {
+	span := func(class, title, text string) string {
+		if title != "" {
+			return fmt.Sprintf(`<span class="%s" title="%s">%s</span>`, class, html.EscapeString(title), text)
+		}
+		return fmt.Sprintf(`<span class="%s">%s</span>`, class, text)
+	}
+
+	var out strings.Builder
+	out.WriteString(htmlStyle)
+	out.WriteString(`<pre class="day03">`)
+	p.render(&out, span, html.EscapeString)
+	out.WriteString("</pre>\n")
+	fmt.Fprintf(&out, "<p>%s</p>\n", html.EscapeString(p.summary()))
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// render writes the input with every instruction and disabled stretch wrapped
+// by span, using the instruction positions to find them. Plain text goes through
+// escape first.
+func (p *AnnotatedProgram) render(out *strings.Builder, span func(class, title, text string) string, escape func(string) string) {
+	// text writes input between two instructions, dimmed while disabled
+	enabled := true
+	text := func(s string) {
+		switch {
+		case s == "":
+		case enabled:
+			out.WriteString(escape(s))
+		default:
+			out.WriteString(span(classDisabled, "", escape(s)))
+		}
+	}
+
+	pos := 0
+	for _, a := range p.Instructions {
+		text(p.Input[pos:a.Position])
+		pos = a.Position + len(a.Value)
+
+		title := fmt.Sprintf("@%d", a.Position)
+		source := escape(p.Input[a.Position:pos])
+		switch {
+		case a.Type != InstructionTypeMul:
+			out.WriteString(span(classSwitch, title, source))
+		case a.Counted:
+			out.WriteString(span(classCounted, title, source))
+			out.WriteString(span(classNote, "", escape(fmt.Sprintf("[+%d =%d]", a.Product, a.Total))))
+		default:
+			out.WriteString(span(classSkipped, title, source))
+		}
+		enabled = a.Enabled
+	}
+	text(p.Input[pos:])
+}
+
+func (p *AnnotatedProgram) summary() string {
+	counted := 0
+	for _, a := range p.Instructions {
+		if a.Counted {
+			counted++
+		}
+	}
+	return fmt.Sprintf("Part %d: %d mul instructions counted, total %d", p.Part, counted, p.Total)
+}
@@ -0,0 +1,99 @@
+package day03
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const part2Example = "xmul(2,4)&mul[3,7]!^don't()_mul(5,5)+mul(32,64](mul(11,8)undo()?mul(8,5))"
+
+func TestAnnotateProgram(t *testing.T) {
+	program, err := AnnotateProgram(part2Example, 2)
+	if err != nil {
+		t.Fatalf("AnnotateProgram failed: %v", err)
+	}
+
+	expected := []struct {
+		position int
+		counted  bool
+		enabled  bool
+		total    int
+	}{
+		{1, true, true, 8},
+		{20, false, false, 8},
+		{28, false, false, 8},
+		{48, false, false, 8},
+		{59, false, true, 8},
+		{64, true, true, 48},
+	}
+
+	if len(program.Instructions) != len(expected) {
+		t.Fatalf("expected %d annotations, got %d", len(expected), len(program.Instructions))
+	}
+	for i, want := range expected {
+		a := program.Instructions[i]
+		if a.Position != want.position || a.Counted != want.counted || a.Enabled != want.enabled || a.Total != want.total {
+			t.Errorf("annotation %d = %+v, expected %+v", i, a, want)
+		}
+	}
+	if program.Total != 48 {
+		t.Errorf("expected total 48, got %d", program.Total)
+	}
+
+	// Part 1 has no do() or don't(), so every mul counts
+	program, err = AnnotateProgram(part2Example, 1)
+	if err != nil {
+		t.Fatalf("AnnotateProgram failed: %v", err)
+	}
+	if len(program.Instructions) != 4 || program.Total != 161 {
+		t.Errorf("part 1: expected 4 instructions totalling 161, got %d totalling %d", len(program.Instructions), program.Total)
+	}
+}
+
+func TestWriteANSI(t *testing.T) {
+	program, err := AnnotateProgram(part2Example, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := program.WriteANSI(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"x" + ansiStyles[classCounted] + "mul(2,4)" + ansiReset + ansiStyles[classNote] + "[+8 =8]" + ansiReset,
+		ansiStyles[classSwitch] + "don't()" + ansiReset + ansiStyles[classDisabled] + "_" + ansiReset,
+		ansiStyles[classSkipped] + "mul(5,5)" + ansiReset,
+		"un" + ansiReset + ansiStyles[classSwitch] + "do()" + ansiReset + "?",
+		ansiStyles[classNote] + "[+40 =48]" + ansiReset + ")\n",
+		"Part 2: 2 mul instructions counted, total 48",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("WriteANSI() output missing %q:\n%q", want, out.String())
+		}
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	program, err := AnnotateProgram("<mul(2,4)>&don't()mul(1,1)", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := program.WriteHTML(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`<pre class="day03">&lt;<span class="counted" title="@1">mul(2,4)</span><span class="note">[+8 =8]</span>&gt;&amp;`,
+		`<span class="switch" title="@11">don&#39;t()</span><span class="skipped" title="@18">mul(1,1)</span></pre>`,
+		"<p>Part 2: 1 mul instructions counted, total 8</p>",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("WriteHTML() output missing %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -15,30 +15,21 @@
 package day03
 
 import (
+	"fmt"
 	"io"
+	"math/big"
 	"os"
-	"regexp"
-	"sort"
 	"strconv"
+
+	"advent-of-code-2024/internal/checked"
+	"advent-of-code-2024/internal/trace"
 )
 
+// Instruction type constants
 const (
-	// Instruction type constants
 	InstructionTypeMul  = "mul"
 	InstructionTypeDo   = "do"
 	InstructionTypeDont = "don't"
-
-	// Regex patterns
-	mulPattern  = `mul\((\d+),(\d+)\)`
-	doPattern   = `do\(\)`
-	dontPattern = `don't\(\)`
-)
-
-var (
-	// Compiled regex patterns (cached for performance)
-	mulRe  = regexp.MustCompile(mulPattern)
-	doRe   = regexp.MustCompile(doPattern)
-	dontRe = regexp.MustCompile(dontPattern)
 )
 
 // Instruction represents a single instruction found in the corrupted memory
@@ -46,6 +37,7 @@ type Instruction struct {
 	Type     string // "mul", "do", or "don't"
 	Position int    // Position in the input string
 	Value    string // The full matched string
+	Args     []string
 }
 
 // parseInput reads the entire file content as corrupted memory
@@ -64,34 +56,80 @@ func parseInput(filename string) (string, error) {
 	return string(content), nil
 }
 
-// extractAndMultiply takes a valid mul instruction and returns the product
-func extractAndMultiply(instruction string) int {
-	matches := mulRe.FindStringSubmatch(instruction)
+// extractAndMultiply takes a valid mul instruction and returns the product, or an
+// overflow error if an operand or the product does not fit in an int
+func extractAndMultiply(instruction string) (int, error) {
+	instructions := part1Instructions.parse(instruction)
+	if len(instructions) != 1 {
+		return 0, nil
+	}
+
+	return multiply(instructions[0])
+}
 
-	if len(matches) != 3 {
-		return 0
+// multiply returns the product of a mul instruction's arguments, or an overflow
+// error if an operand or the product does not fit in an int
+func multiply(instruction Instruction) (int, error) {
+	// Arguments are digits, with a leading + or - when the grammar allows
+	// signs, which Atoi accepts, so it can only fail on range
+	x, err := strconv.Atoi(instruction.Args[0])
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", checked.ErrOverflow, instruction.Value)
+	}
+	y, err := strconv.Atoi(instruction.Args[1])
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", checked.ErrOverflow, instruction.Value)
 	}
 
-	x, _ := strconv.Atoi(matches[1])
-	y, _ := strconv.Atoi(matches[2])
+	product, ok := checked.Mul(x, y)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", checked.ErrOverflow, instruction.Value)
+	}
 
-	return x * y
+	return product, nil
 }
 
-// processCorruptedMemory processes corrupted memory and returns sum of all valid multiplications
-// This is now implemented using the same infrastructure as Part 2, but ignores conditional instructions
-func processCorruptedMemory(input string) int {
-	instructions := findAllInstructions(input)
-	total := 0
+// multiplyBig is multiply for operands of any size
+func multiplyBig(instruction Instruction) *big.Int {
+	x, _ := new(big.Int).SetString(instruction.Args[0], 10)
+	y, _ := new(big.Int).SetString(instruction.Args[1], 10)
 
-	for _, instruction := range instructions {
-		if instruction.Type == InstructionTypeMul {
-			total += extractAndMultiply(instruction.Value)
-		}
-		// Ignore do() and don't() instructions in Part 1
+	return x.Mul(x, y)
+}
+
+// addProduct adds the product of a mul instruction to total, multiplying with
+// math/big when it does not fit in an int, and returns the product for tracing
+func addProduct(total *checked.Sum, instruction Instruction) (any, error) {
+	var product any
+	if p, err := multiply(instruction); err == nil {
+		total.Add(p)
+		product = p
+	} else {
+		p := multiplyBig(instruction)
+		total.AddBig(p)
+		product = p
+	}
+
+	if err := total.Err(); err != nil {
+		return nil, fmt.Errorf("@%d %s: %w", instruction.Position, instruction.Value, err)
+	}
+	return product, nil
+}
+
+// processCorruptedMemory processes corrupted memory and returns sum of all valid multiplications
+// It runs the interpreter with an instruction table that only knows mul
+func processCorruptedMemory(input string) (int, error) {
+	total, err := sumCorruptedMemory(input, checked.Strict)
+	if err != nil {
+		return 0, err
 	}
 
-	return total
+	return total.Int()
+}
+
+// sumCorruptedMemory is processCorruptedMemory with a choice of overflow behaviour
+func sumCorruptedMemory(input string, mode checked.Mode) (*checked.Sum, error) {
+	return part1Instructions.run(input, mode)
 }
 
 // SolvePart1 reads input file and returns sum of all valid mul instruction results
@@ -101,61 +139,70 @@ func SolvePart1(filename string) (int, error) {
 		return 0, err
 	}
 
-	return processCorruptedMemory(content), nil
+	return processCorruptedMemory(content)
 }
 
-// findInstructionsByRegex is a helper function to find instructions using a compiled regex
-func findInstructionsByRegex(input string, instructionType string, re *regexp.Regexp) []Instruction {
-	matches := re.FindAllStringIndex(input, -1)
+// SolvePart1Mode is SolvePart1 with a choice of overflow behaviour. In Promote
+// mode the exact sum is returned however large it grows.
+func SolvePart1Mode(filename string, mode checked.Mode) (*big.Int, error) {
+	content, err := parseInput(filename)
+	if err != nil {
+		return nil, err
+	}
 
-	var instructions []Instruction
-	for _, match := range matches {
-		instructions = append(instructions, Instruction{
-			Type:     instructionType,
-			Position: match[0],
-			Value:    input[match[0]:match[1]],
-		})
+	total, err := sumCorruptedMemory(content, mode)
+	if err != nil {
+		return nil, err
 	}
-	return instructions
+
+	return total.Big()
 }
 
 // findAllInstructions finds all valid instructions (mul, do, don't) with their positions
 func findAllInstructions(input string) []Instruction {
-	var instructions []Instruction
+	return part2Instructions.parse(input)
+}
 
-	// Find all instruction types using cached compiled regexes
-	instructions = append(instructions, findInstructionsByRegex(input, InstructionTypeMul, mulRe)...)
-	instructions = append(instructions, findInstructionsByRegex(input, InstructionTypeDo, doRe)...)
-	instructions = append(instructions, findInstructionsByRegex(input, InstructionTypeDont, dontRe)...)
+// processWithConditionals processes corrupted memory with conditional instructions
+func processWithConditionals(input string) (int, error) {
+	total, err := sumWithConditionals(input, checked.Strict)
+	if err != nil {
+		return 0, err
+	}
 
-	// Sort by position to process in order
-	sort.Slice(instructions, func(i, j int) bool {
-		return instructions[i].Position < instructions[j].Position
-	})
+	return total.Int()
+}
 
-	return instructions
+// sumWithConditionals is processWithConditionals with a choice of overflow behaviour
+func sumWithConditionals(input string, mode checked.Mode) (*checked.Sum, error) {
+	return part2Instructions.run(input, mode)
 }
 
-// processWithConditionals processes corrupted memory with conditional instructions
-func processWithConditionals(input string) int {
-	instructions := findAllInstructions(input)
-	enabled := true // mul instructions are enabled at the beginning
-	total := 0
+// traceInstruction reports an instruction, the enabled flag after it ran, and
+// what it added to the total
+func traceInstruction(instruction Instruction, enabled bool, product any, total *checked.Sum) {
+	if !trace.Enabled() {
+		return
+	}
 
-	for _, instruction := range instructions {
-		switch instruction.Type {
-		case InstructionTypeDo:
-			enabled = true
-		case InstructionTypeDont:
-			enabled = false
-		case InstructionTypeMul:
-			if enabled {
-				total += extractAndMultiply(instruction.Value)
-			}
-		}
+	var message string
+	switch {
+	case product == nil:
+		message = fmt.Sprintf("@%d %s: mul instructions now enabled=%t", instruction.Position, instruction.Value, enabled)
+	case enabled:
+		message = fmt.Sprintf("@%d %s = %v (running total %s)", instruction.Position, instruction.Value, product, total)
+	default:
+		message = fmt.Sprintf("@%d %s skipped while disabled", instruction.Position, instruction.Value)
 	}
 
-	return total
+	trace.Emit("instruction", message, map[string]any{
+		"type":     instruction.Type,
+		"position": instruction.Position,
+		"value":    instruction.Value,
+		"enabled":  enabled,
+		"product":  product,
+		"total":    total.Value(),
+	})
 }
 
 // SolvePart2 reads input file and returns sum of enabled mul instruction results
@@ -165,5 +212,21 @@ func SolvePart2(filename string) (int, error) {
 		return 0, err
 	}
 
-	return processWithConditionals(content), nil
+	return processWithConditionals(content)
+}
+
+// SolvePart2Mode is SolvePart2 with a choice of overflow behaviour. In Promote
+// mode the exact sum is returned however large it grows.
+func SolvePart2Mode(filename string, mode checked.Mode) (*big.Int, error) {
+	content, err := parseInput(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := sumWithConditionals(content, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	return total.Big()
 }
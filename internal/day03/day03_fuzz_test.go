@@ -0,0 +1,62 @@
+package day03
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"advent-of-code-2024/internal/checked"
+)
+
+func addExampleSeeds(f *testing.F) {
+	for _, name := range []string{"example-input.txt", "example-part2-input.txt"} {
+		example, err := os.ReadFile(name)
+		if err != nil {
+			f.Fatalf("failed to read %s: %v", name, err)
+		}
+		f.Add(string(example))
+	}
+	f.Add("")
+	f.Add("mul(1,2")
+	f.Add("don't()do()don't()mul(3,4)")
+	f.Add("mul(99999999999999999999,2)")
+}
+
+func FuzzFindAllInstructions(f *testing.F) {
+	addExampleSeeds(f)
+
+	f.Fuzz(func(t *testing.T, data string) {
+		instructions := findAllInstructions(data)
+		for i, instruction := range instructions {
+			if i > 0 && instruction.Position < instructions[i-1].Position {
+				t.Fatalf("instruction %d at %d is before instruction %d at %d",
+					i, instruction.Position, i-1, instructions[i-1].Position)
+			}
+			if !strings.HasPrefix(data[instruction.Position:], instruction.Value) {
+				t.Fatalf("instruction %q not found at position %d", instruction.Value, instruction.Position)
+			}
+		}
+	})
+}
+
+func FuzzProcessWithConditionals(f *testing.F) {
+	addExampleSeeds(f)
+
+	f.Fuzz(func(t *testing.T, data string) {
+		part1, err1 := processCorruptedMemory(data)
+		part2, err2 := processWithConditionals(data)
+		if err1 != nil || err2 != nil {
+			// Overflow is the only way either part may fail
+			if err1 != nil && !errors.Is(err1, checked.ErrOverflow) || err2 != nil && !errors.Is(err2, checked.ErrOverflow) {
+				t.Fatalf("unexpected errors %v, %v", err1, err2)
+			}
+			return
+		}
+
+		// Without any don't() every mul stays enabled, so both parts agree
+		if !strings.Contains(data, "don't()") && part1 != part2 {
+			t.Fatalf("part 1 = %d, part 2 = %d for input without don't()", part1, part2)
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package day03
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"advent-of-code-2024/internal/difftest"
+)
+
+// referenceMul reports the product of a mul(X,Y) instruction starting exactly at s
+func referenceMul(s string) (int, bool) {
+	if !strings.HasPrefix(s, "mul(") {
+		return 0, false
+	}
+	rest := s[len("mul("):]
+
+	readNumber := func() (int, bool) {
+		digits := 0
+		for digits < len(rest) && rest[digits] >= '0' && rest[digits] <= '9' {
+			digits++
+		}
+		if digits == 0 {
+			return 0, false
+		}
+		n, _ := strconv.Atoi(rest[:digits])
+		rest = rest[digits:]
+		return n, true
+	}
+
+	x, ok := readNumber()
+	if !ok || !strings.HasPrefix(rest, ",") {
+		return 0, false
+	}
+	rest = rest[1:]
+	y, ok := readNumber()
+	if !ok || !strings.HasPrefix(rest, ")") {
+		return 0, false
+	}
+	return x * y, true
+}
+
+// referenceScan tries every offset of the input in turn
+func referenceScan(input string, conditionals bool) int {
+	enabled := true
+	total := 0
+	for i := range input {
+		switch {
+		case conditionals && strings.HasPrefix(input[i:], "do()"):
+			enabled = true
+		case conditionals && strings.HasPrefix(input[i:], "don't()"):
+			enabled = false
+		default:
+			if product, ok := referenceMul(input[i:]); ok && enabled {
+				total += product
+			}
+		}
+	}
+	return total
+}
+
+func referencePart1(input string) (int, error) {
+	return referenceScan(input, false), nil
+}
+
+func referencePart2(input string) (int, error) {
+	return referenceScan(input, true), nil
+}
+
+func TestDifferential(t *testing.T) {
+	difftest.Run(t, 3, []int{1, 10, 1000},
+		difftest.Part{Solve: SolvePart1, Reference: referencePart1},
+		difftest.Part{Solve: SolvePart2, Reference: referencePart2})
+}
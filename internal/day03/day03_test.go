@@ -1,7 +1,13 @@
 package day03
 
 import (
+	"errors"
+	"math/big"
+	"os"
 	"testing"
+
+	"advent-of-code-2024/internal/checked"
+	"advent-of-code-2024/internal/trace"
 )
 
 func TestExtractAndMultiply(t *testing.T) {
@@ -34,7 +40,10 @@ func TestExtractAndMultiply(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := extractAndMultiply(tt.input)
+			result, err := extractAndMultiply(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
 			if result != tt.expected {
 				t.Errorf("expected %d, got %d", tt.expected, result)
 			}
@@ -67,7 +76,10 @@ func TestProcessCorruptedMemory(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := processCorruptedMemory(tt.input)
+			result, err := processCorruptedMemory(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
 			if result != tt.expected {
 				t.Errorf("expected %d, got %d", tt.expected, result)
 			}
@@ -174,7 +186,10 @@ func TestProcessWithConditionals(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := processWithConditionals(tt.input)
+			result, err := processWithConditionals(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
 			if result != tt.expected {
 				t.Errorf("expected %d, got %d", tt.expected, result)
 			}
@@ -193,3 +208,96 @@ func TestSolvePart2(t *testing.T) {
 		t.Errorf("expected %d, got %d", expected, result)
 	}
 }
+
+func TestTraceEvents(t *testing.T) {
+	var events []trace.Event
+	trace.SetHook(func(e trace.Event) { events = append(events, e) })
+	defer trace.SetHook(nil)
+
+	_, _ = processWithConditionals("xmul(2,4)&mul[3,7]!^don't()_mul(5,5)+mul(32,64](mul(11,8)undo()?mul(8,5))")
+
+	expected := []string{
+		"@1 mul(2,4) = 8 (running total 8)",
+		"@20 don't(): mul instructions now enabled=false",
+		"@28 mul(5,5) skipped while disabled",
+		"@48 mul(11,8) skipped while disabled",
+		"@59 do(): mul instructions now enabled=true",
+		"@64 mul(8,5) = 40 (running total 48)",
+	}
+
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %d", len(expected), len(events))
+	}
+	for i, message := range expected {
+		if events[i].Message != message {
+			t.Errorf("event %d: expected %q, got %q", i, message, events[i].Message)
+		}
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string // exact sum in Promote mode
+	}{
+		{
+			name:     "product past int64",
+			input:    "mul(4294967296,4294967296)",
+			expected: "18446744073709551616",
+		},
+		{
+			name:     "sum past int64",
+			input:    "mul(3037000499,3037000499)mul(3037000499,3037000499)",
+			expected: "18446744061852498002",
+		},
+		{
+			name:     "operand past int64",
+			input:    "mul(99999999999999999999,2)",
+			expected: "199999999999999999998",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := t.TempDir() + "/input.txt"
+			if err := os.WriteFile(path, []byte(tt.input), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := SolvePart1(path); !errors.Is(err, checked.ErrOverflow) {
+				t.Errorf("SolvePart1() error = %v, expected ErrOverflow", err)
+			}
+			if _, err := SolvePart2(path); !errors.Is(err, checked.ErrOverflow) {
+				t.Errorf("SolvePart2() error = %v, expected ErrOverflow", err)
+			}
+
+			for part, solve := range map[int]func(string, checked.Mode) (*big.Int, error){1: SolvePart1Mode, 2: SolvePart2Mode} {
+				result, err := solve(path, checked.Promote)
+				if err != nil {
+					t.Fatalf("SolvePart%dMode() error = %v", part, err)
+				}
+				if result.String() != tt.expected {
+					t.Errorf("SolvePart%dMode() = %s, expected %s", part, result, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestOverflowWhileDisabled(t *testing.T) {
+	// A huge mul that part 2 skips only breaks part 1
+	input := "mul(2,3)don't()mul(4294967296,4294967296)"
+
+	if _, err := processCorruptedMemory(input); !errors.Is(err, checked.ErrOverflow) {
+		t.Errorf("processCorruptedMemory() error = %v, expected ErrOverflow", err)
+	}
+	if result, err := processWithConditionals(input); err != nil || result != 6 {
+		t.Errorf("processWithConditionals() = %d, %v, expected 6", result, err)
+	}
+
+	// An operand too long for an int is fine if the product fits
+	if result, err := processCorruptedMemory("mul(99999999999999999999,0)mul(2,4)"); err != nil || result != 8 {
+		t.Errorf("processCorruptedMemory() = %d, %v, expected 8", result, err)
+	}
+}
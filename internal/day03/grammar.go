@@ -0,0 +1,181 @@
+package day03
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"advent-of-code-2024/internal/checked"
+)
+
+// Grammar sets how an instruction's arguments may be spelled. The zero value is
+// what the solvers accept: numbers of any length, with no signs or whitespace.
+type Grammar struct {
+	MinDigits  int    // fewest digits in a number; 0 means 1
+	MaxDigits  int    // most digits in a number; 0 means no limit
+	Space      string // whitespace characters allowed before "(" and around arguments
+	AllowSigns bool   // a number may start with + or -
+}
+
+// PuzzleGrammar returns the puzzle's rules: each number is 1-3 digits
+func PuzzleGrammar() Grammar {
+	return Grammar{MinDigits: 1, MaxDigits: 3}
+}
+
+// Validate checks that the grammar's limits make sense
+func (g Grammar) Validate() error {
+	if g.MinDigits < 0 || g.MaxDigits < 0 {
+		return fmt.Errorf("digit limits must not be negative, got %d-%d", g.MinDigits, g.MaxDigits)
+	}
+	if g.MaxDigits > 0 && g.MaxDigits < g.minDigits() {
+		return fmt.Errorf("maximum of %d digits is below minimum of %d", g.MaxDigits, g.minDigits())
+	}
+	for i := 0; i < len(g.Space); i++ {
+		if !isSpace(g.Space[i]) {
+			return fmt.Errorf("%q is not a whitespace character", g.Space[i])
+		}
+	}
+	return nil
+}
+
+func (g Grammar) minDigits() int {
+	return max(g.MinDigits, 1)
+}
+
+// digitRange describes the allowed number of digits, e.g. "1-3 digits"
+func (g Grammar) digitRange() string {
+	if g.MaxDigits == 0 {
+		return fmt.Sprintf("at least %d digits", g.minDigits())
+	}
+	return fmt.Sprintf("%d-%d digits", g.minDigits(), g.MaxDigits)
+}
+
+// MissKind names the way a rejected instruction went wrong
+type MissKind string
+
+const (
+	MissMalformed MissKind = "malformed"   // the call is cut short, e.g. mul(4*
+	MissSpace     MissKind = "whitespace"  // e.g. mul ( 2 , 4 )
+	MissSign      MissKind = "sign"        // e.g. mul(-2,4)
+	MissDigits    MissKind = "digit count" // e.g. mul(1234,5)
+	MissArity     MissKind = "arguments"   // e.g. mul(2) or do(1)
+)
+
+// missKinds lists every kind in the order reports show them
+var missKinds = []MissKind{MissMalformed, MissSpace, MissSign, MissDigits, MissArity}
+
+// NearMiss is a call of a known instruction that the grammar rejected
+type NearMiss struct {
+	Position int      `json:"position"` // byte offset of the instruction name
+	Text     string   `json:"text"`     // the call up to where it went wrong
+	Kind     MissKind `json:"kind"`
+	Reason   string   `json:"reason"`
+}
+
+// Audit explains a total: how many mul instructions one part accepted under a
+// grammar and counted while enabled, and every call it rejected with the reason
+type Audit struct {
+	Part       int              `json:"part"`
+	Accepted   int              `json:"accepted"`
+	Counted    int              `json:"counted"`
+	Total      int              `json:"total"`
+	Misses     map[MissKind]int `json:"misses"`
+	NearMisses []NearMiss       `json:"nearMisses"`
+}
+
+// AuditFile reads corrupted memory from filename and audits it
+func AuditFile(filename string, part int, grammar Grammar) (*Audit, error) {
+	content, err := parseInput(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return AuditProgram(content, part, grammar)
+}
+
+// AuditProgram runs input the way part 1 or part 2 does under grammar,
+// collecting the near misses along the way
+func AuditProgram(input string, part int, grammar Grammar) (*Audit, error) {
+	if err := grammar.Validate(); err != nil {
+		return nil, err
+	}
+
+	set := instructionsFor(part)
+	audit := &Audit{Part: part, Misses: make(map[MissKind]int), NearMisses: []NearMiss{}}
+	p := newParser(set, &lexer{input: input}, grammar)
+	p.nearMiss = func(miss NearMiss) {
+		audit.Misses[miss.Kind]++
+		audit.NearMisses = append(audit.NearMisses, miss)
+	}
+
+	total, err := set.interpret(p, checked.Strict, func(instruction Instruction, enabled bool, _ any, _ *checked.Sum) {
+		if instruction.Type != InstructionTypeMul {
+			return
+		}
+		audit.Accepted++
+		if enabled {
+			audit.Counted++
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if audit.Total, err = total.Int(); err != nil {
+		return nil, err
+	}
+	return audit, nil
+}
+
+// WriteTable writes the audit as a summary, the near misses counted by kind
+// and each near miss with its offset
+func (a *Audit) WriteTable(w io.Writer) error {
+	var out strings.Builder
+
+	fmt.Fprintf(&out, "Part %d: %d mul instructions accepted, %d counted, total %d\n", a.Part, a.Accepted, a.Counted, a.Total)
+	fmt.Fprintf(&out, "\nNear misses by kind\n")
+	for _, kind := range missKinds {
+		fmt.Fprintf(&out, "  %-12s %8d\n", kind, a.Misses[kind])
+	}
+
+	fmt.Fprintf(&out, "\nNear misses (%d)\n", len(a.NearMisses))
+	for _, miss := range a.NearMisses {
+		fmt.Fprintf(&out, "  @%-8d %-24q %s\n", miss.Position, miss.Text, miss.Reason)
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// SolveWithGrammar solves part 1 or part 2 accepting only instructions that
+// follow grammar
+func SolveWithGrammar(filename string, part int, grammar Grammar) (int, error) {
+	total, err := solveWithGrammar(filename, part, grammar, checked.Strict)
+	if err != nil {
+		return 0, err
+	}
+	return total.Int()
+}
+
+// SolveWithGrammarMode is SolveWithGrammar with a choice of overflow
+// behaviour, as SolvePart1Mode and SolvePart2Mode are for the default grammar
+func SolveWithGrammarMode(filename string, part int, grammar Grammar, mode checked.Mode) (*big.Int, error) {
+	total, err := solveWithGrammar(filename, part, grammar, mode)
+	if err != nil {
+		return nil, err
+	}
+	return total.Big()
+}
+
+func solveWithGrammar(filename string, part int, grammar Grammar, mode checked.Mode) (*checked.Sum, error) {
+	if err := grammar.Validate(); err != nil {
+		return nil, err
+	}
+
+	content, err := parseInput(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return instructionsFor(part).runWith(content, grammar, mode, traceInstruction)
+}
@@ -0,0 +1,137 @@
+package day03
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"advent-of-code-2024/internal/checked"
+)
+
+func TestGrammarValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		grammar Grammar
+		wantErr bool
+	}{
+		{"zero value", Grammar{}, false},
+		{"puzzle", PuzzleGrammar(), false},
+		{"spaces and signs", Grammar{Space: " \t", AllowSigns: true}, false},
+		{"negative", Grammar{MaxDigits: -1}, true},
+		{"max below min", Grammar{MinDigits: 4, MaxDigits: 3}, true},
+		{"not whitespace", Grammar{Space: " x"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.grammar.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGrammarAcceptance(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		grammar  Grammar
+		expected int
+	}{
+		{"zero value allows long numbers", "mul(1234,5)mul(2,3)", Grammar{}, 6176},
+		{"puzzle rejects long numbers", "mul(1234,5)mul(2,3)", PuzzleGrammar(), 6},
+		{"minimum digits", "mul(10,20)mul(2,3)", Grammar{MinDigits: 2}, 200},
+		{"spaces rejected", "mul ( 2 , 4 )", Grammar{}, 0},
+		{"spaces allowed", "mul ( 2 , 4 )mul(\t3,3)", Grammar{Space: " "}, 8},
+		{"signs rejected", "mul(-2,4)mul(+3,3)", Grammar{}, 0},
+		{"signs allowed", "mul(-2,4)mul(+3,3)", Grammar{AllowSigns: true}, 1},
+		{"sign needs digits", "mul(-,4)mul(- 2,4)", Grammar{Space: " ", AllowSigns: true}, 0},
+		{"interrupted call resumes", "mul(2,mul(3,4)", PuzzleGrammar(), 12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total, err := part1Instructions.runWith(tt.input, tt.grammar, checked.Strict, func(Instruction, bool, any, *checked.Sum) {})
+			if err != nil {
+				t.Fatalf("runWith failed: %v", err)
+			}
+			if result, _ := total.Int(); result != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestAuditProgram(t *testing.T) {
+	input := "xmul(4*mul ( 2 , 4 )mul(1234,5)mul(-2,4)mul(2)do(1)mul(32,64]mul(1,2,3)mul(2,4)don't()mul(3,3)mul(5,"
+	audit, err := AuditProgram(input, 2, PuzzleGrammar())
+	if err != nil {
+		t.Fatalf("AuditProgram failed: %v", err)
+	}
+
+	expected := []NearMiss{
+		{Position: 1, Text: "mul(4", Kind: MissMalformed, Reason: `expected "," or ")", found "*"`},
+		{Position: 7, Text: "mul ( 2 , 4 )", Kind: MissSpace, Reason: `whitespace " " not allowed`},
+		{Position: 20, Text: "mul(1234,5)", Kind: MissDigits, Reason: "1234 has 4 digits, expected 1-3 digits"},
+		{Position: 31, Text: "mul(-2,4)", Kind: MissSign, Reason: `sign "-" not allowed`},
+		{Position: 40, Text: "mul(2)", Kind: MissArity, Reason: "mul takes 2 arguments, found 1"},
+		{Position: 46, Text: "do(1)", Kind: MissArity, Reason: "do takes 0 arguments, found 1"},
+		{Position: 51, Text: "mul(32,64", Kind: MissMalformed, Reason: `expected "," or ")", found "]"`},
+		{Position: 61, Text: "mul(1,2,", Kind: MissArity, Reason: "more than 2 arguments"},
+		{Position: 94, Text: "mul(5,", Kind: MissMalformed, Reason: "unexpected end of input"},
+	}
+
+	if len(audit.NearMisses) != len(expected) {
+		t.Fatalf("expected %d near misses, got %d: %+v", len(expected), len(audit.NearMisses), audit.NearMisses)
+	}
+	for i, miss := range audit.NearMisses {
+		if miss != expected[i] {
+			t.Errorf("near miss %d: expected %+v, got %+v", i, expected[i], miss)
+		}
+	}
+
+	if audit.Accepted != 2 || audit.Counted != 1 || audit.Total != 8 {
+		t.Errorf("expected 2 accepted, 1 counted, total 8, got %d, %d, %d", audit.Accepted, audit.Counted, audit.Total)
+	}
+	if audit.Misses[MissMalformed] != 3 || audit.Misses[MissArity] != 3 {
+		t.Errorf("unexpected counts by kind %v", audit.Misses)
+	}
+
+	if _, err := AuditProgram(input, 2, Grammar{MaxDigits: -1}); err == nil {
+		t.Error("AuditProgram should reject an invalid grammar")
+	}
+}
+
+func TestAuditWriteTable(t *testing.T) {
+	audit, err := AuditProgram("mul(2,4)mul(1234,5)", 1, PuzzleGrammar())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := audit.WriteTable(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"Part 1: 1 mul instructions accepted, 1 counted, total 8",
+		"  digit count         1",
+		`  @8        "mul(1234,5)"            1234 has 4 digits, expected 1-3 digits`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("WriteTable() output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestSolveWithGrammar(t *testing.T) {
+	for part, expected := range map[int]int{1: 161, 2: 48} {
+		result, err := SolveWithGrammar("example-part2-input.txt", part, PuzzleGrammar())
+		if err != nil {
+			t.Fatalf("SolveWithGrammar failed: %v", err)
+		}
+		if result != expected {
+			t.Errorf("part %d: expected %d, got %d", part, expected, result)
+		}
+	}
+}
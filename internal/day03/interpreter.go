@@ -0,0 +1,327 @@
+package day03
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"advent-of-code-2024/internal/checked"
+)
+
+// operation is an entry in an instruction table
+type operation struct {
+	arity int // number of comma-separated numeric arguments
+	// apply runs the instruction against the machine and returns what it added
+	// to the total, or nil for instructions that only change state
+	apply func(m *machine, instruction Instruction) (any, error)
+}
+
+// instructionSet maps instruction names to their operations. Adding an entry
+// is all it takes for the parser to recognise a new instruction.
+type instructionSet map[string]operation
+
+var (
+	// part1Instructions only multiplies
+	part1Instructions = instructionSet{
+		InstructionTypeMul: {arity: 2, apply: applyMul},
+	}
+
+	// part2Instructions adds the do() and don't() switches
+	part2Instructions = instructionSet{
+		InstructionTypeMul:  {arity: 2, apply: applyMul},
+		InstructionTypeDo:   {arity: 0, apply: applyEnable(true)},
+		InstructionTypeDont: {arity: 0, apply: applyEnable(false)},
+	}
+)
+
+// instructionsFor returns the instruction table of a puzzle part
+func instructionsFor(part int) instructionSet {
+	if part == 2 {
+		return part2Instructions
+	}
+	return part1Instructions
+}
+
+// machine is the interpreter state that instructions act on
+type machine struct {
+	enabled bool
+	total   *checked.Sum
+}
+
+func applyMul(m *machine, instruction Instruction) (any, error) {
+	if !m.enabled {
+		return 0, nil
+	}
+	return addProduct(m.total, instruction)
+}
+
+func applyEnable(enabled bool) func(*machine, Instruction) (any, error) {
+	return func(m *machine, _ Instruction) (any, error) {
+		m.enabled = enabled
+		return nil, nil
+	}
+}
+
+// names returns the instruction names longest first, so that a word ending in
+// several of them picks the most specific one
+func (set instructionSet) names() []string {
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) > len(names[j])
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// maxArity returns the most arguments any instruction in set takes
+func (set instructionSet) maxArity() int {
+	arity := 0
+	for _, op := range set {
+		arity = max(arity, op.arity)
+	}
+	return arity
+}
+
+// parse finds the instructions of set in input
+func (set instructionSet) parse(input string) []Instruction {
+	var instructions []Instruction
+	p := newParser(set, &lexer{input: input}, Grammar{})
+	for {
+		instruction, ok := p.next()
+		if !ok {
+			return instructions
+		}
+		instructions = append(instructions, instruction)
+	}
+}
+
+// tokenSource yields tokens in input order
+type tokenSource interface {
+	next() (Token, bool)
+}
+
+// parser reads instructions from a token source one at a time. An instruction
+// is a word ending in one of the set's names followed by the operation's
+// arguments in parentheses, spelled as the grammar allows; everything else is
+// corruption and is skipped. Only a word can start an instruction, so when a
+// call turns out to be malformed the parser resumes at the token that broke it.
+type parser struct {
+	set      instructionSet
+	names    []string
+	maxArity int
+	grammar  Grammar
+	tokens   tokenSource
+	pending  *Token // a token read ahead and not yet consumed
+
+	// nearMiss, if set, is called for each call of a known instruction that the
+	// parser rejects
+	nearMiss func(NearMiss)
+}
+
+func newParser(set instructionSet, tokens tokenSource, grammar Grammar) *parser {
+	return &parser{set: set, names: set.names(), maxArity: set.maxArity(), grammar: grammar, tokens: tokens}
+}
+
+func (p *parser) read() (Token, bool) {
+	if p.pending != nil {
+		token := *p.pending
+		p.pending = nil
+		return token, true
+	}
+	return p.tokens.next()
+}
+
+func (p *parser) unread(token Token) {
+	p.pending = &token
+}
+
+// next returns the next instruction, or false once the tokens run out
+func (p *parser) next() (Instruction, bool) {
+	for {
+		word, ok := p.read()
+		if !ok {
+			return Instruction{}, false
+		}
+		if word.Kind != TokenWord {
+			continue
+		}
+		name, ok := p.match(word.Text, -1)
+		if !ok {
+			continue
+		}
+
+		var source strings.Builder
+		source.WriteString(name)
+		args, problem, isCall := p.readCall(&source)
+		if !isCall {
+			continue
+		}
+
+		position := word.End() - len(name)
+		if problem == nil {
+			if name, ok := p.match(word.Text, len(args)); ok {
+				return Instruction{
+					Type:     name,
+					Position: position,
+					Value:    source.String(),
+					Args:     args,
+				}, true
+			}
+			problem = &NearMiss{Kind: MissArity, Reason: fmt.Sprintf("%s takes %d arguments, found %d", name, p.set[name].arity, len(args))}
+		}
+
+		if p.nearMiss != nil {
+			problem.Position, problem.Text = position, source.String()
+			p.nearMiss(*problem)
+		}
+	}
+}
+
+// readCall reads the parenthesised arguments after an instruction name into
+// source, returning false if there is no opening parenthesis. Spaces, signs
+// and numbers of any length are read whatever the grammar says, so that calls
+// breaking it can be reported; the first such problem is returned.
+func (p *parser) readCall(source *strings.Builder) (args []string, problem *NearMiss, isCall bool) {
+	note := func(kind MissKind, format string, a ...any) {
+		if problem == nil {
+			problem = &NearMiss{Kind: kind, Reason: fmt.Sprintf(format, a...)}
+		}
+	}
+
+	// next returns the next token after any whitespace
+	next := func() (Token, bool) {
+		for {
+			token, ok := p.read()
+			if !ok || token.Kind != TokenSpace {
+				return token, ok
+			}
+			if !strings.Contains(p.grammar.Space, token.Text) {
+				note(MissSpace, "whitespace %q not allowed", token.Text)
+			}
+			source.WriteString(token.Text)
+		}
+	}
+
+	token, ok := next()
+	if !ok {
+		return nil, nil, false
+	}
+	if token.Kind != TokenLParen {
+		p.unread(token)
+		return nil, nil, false
+	}
+	source.WriteString(token.Text)
+
+	const (
+		afterOpen = iota
+		afterArg
+		afterComma
+	)
+	expected := [...]string{afterOpen: `a number or ")"`, afterArg: `"," or ")"`, afterComma: "a number"}
+	state := afterOpen
+
+	for {
+		token, ok := next()
+		if !ok {
+			return nil, &NearMiss{Kind: MissMalformed, Reason: "unexpected end of input"}, true
+		}
+
+		switch {
+		case token.Kind == TokenRParen && state != afterComma:
+			source.WriteString(token.Text)
+			return args, problem, true
+		case token.Kind == TokenComma && state == afterArg:
+			source.WriteString(token.Text)
+			state = afterComma
+		case (token.Kind == TokenNumber || token.Kind == TokenSign) && state != afterArg:
+			arg, malformed := p.readNumber(token, note)
+			if malformed != nil {
+				return nil, malformed, true
+			}
+			if len(args) == p.maxArity {
+				return nil, &NearMiss{Kind: MissArity, Reason: fmt.Sprintf("more than %d arguments", p.maxArity)}, true
+			}
+			source.WriteString(arg)
+			args = append(args, arg)
+			state = afterArg
+		default:
+			p.unread(token)
+			return nil, &NearMiss{Kind: MissMalformed, Reason: fmt.Sprintf("expected %s, found %q", expected[state], token.Text)}, true
+		}
+	}
+}
+
+// readNumber reads an argument starting at token, which is a number or a sign
+// directly followed by one, noting any way it breaks the grammar
+func (p *parser) readNumber(token Token, note func(MissKind, string, ...any)) (string, *NearMiss) {
+	sign := ""
+	if token.Kind == TokenSign {
+		sign = token.Text
+		if !p.grammar.AllowSigns {
+			note(MissSign, "sign %q not allowed", sign)
+		}
+
+		next, ok := p.read()
+		if !ok {
+			return "", &NearMiss{Kind: MissMalformed, Reason: "unexpected end of input"}
+		}
+		if next.Kind != TokenNumber {
+			p.unread(next)
+			return "", &NearMiss{Kind: MissMalformed, Reason: fmt.Sprintf("expected digits after %q, found %q", sign, next.Text)}
+		}
+		token = next
+	}
+
+	if digits := len(token.Text); digits < p.grammar.minDigits() || p.grammar.MaxDigits > 0 && digits > p.grammar.MaxDigits {
+		note(MissDigits, "%s has %d digits, expected %s", token.Text, digits, p.grammar.digitRange())
+	}
+	return sign + token.Text, nil
+}
+
+// match finds the longest instruction name that word ends with and that takes
+// arity arguments, or any number of arguments if arity is negative
+func (p *parser) match(word string, arity int) (string, bool) {
+	for _, name := range p.names {
+		if (arity < 0 || p.set[name].arity == arity) && strings.HasSuffix(word, name) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// run parses input with set and interprets the instructions in order, tracing each
+func (set instructionSet) run(input string, mode checked.Mode) (*checked.Sum, error) {
+	return set.runWith(input, Grammar{}, mode, traceInstruction)
+}
+
+// runWith is run under grammar with a callback in place of tracing
+func (set instructionSet) runWith(input string, grammar Grammar, mode checked.Mode, visit visitFunc) (*checked.Sum, error) {
+	return set.interpret(newParser(set, &lexer{input: input}, grammar), mode, visit)
+}
+
+// visitFunc sees each instruction after it ran, with the enabled flag and total
+// at that point and what the instruction added, as described for operation.apply
+type visitFunc func(instruction Instruction, enabled bool, product any, total *checked.Sum)
+
+// interpret runs the instructions p finds in order
+func (set instructionSet) interpret(p *parser, mode checked.Mode, visit visitFunc) (*checked.Sum, error) {
+	m := &machine{enabled: true, total: checked.NewSum(mode)}
+
+	for {
+		instruction, ok := p.next()
+		if !ok {
+			return m.total, nil
+		}
+
+		product, err := set[instruction.Type].apply(m, instruction)
+		if err != nil {
+			return nil, err
+		}
+		visit(instruction, m.enabled, product, m.total)
+	}
+}
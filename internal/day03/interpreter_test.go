@@ -0,0 +1,75 @@
+package day03
+
+import (
+	"strconv"
+	"testing"
+
+	"advent-of-code-2024/internal/checked"
+)
+
+func TestParseArguments(t *testing.T) {
+	instructions := part2Instructions.parse("mul(12,345)undo()mul(1,2,3)mul(4,5")
+	if len(instructions) != 2 {
+		t.Fatalf("expected 2 instructions, got %d: %+v", len(instructions), instructions)
+	}
+
+	if args := instructions[0].Args; len(args) != 2 || args[0] != "12" || args[1] != "345" {
+		t.Errorf("mul arguments: expected [12 345], got %v", args)
+	}
+	if instructions[1].Type != InstructionTypeDo || instructions[1].Position != 13 || len(instructions[1].Args) != 0 {
+		t.Errorf("expected do() at 13 with no arguments, got %+v", instructions[1])
+	}
+}
+
+func TestInstructionSetExtension(t *testing.T) {
+	// New instructions need only a table entry: an add(X,Y) that sums its
+	// arguments and a reset(N) that disables the next N arithmetic instructions
+	skip := 0
+	applyAdd := func(m *machine, instruction Instruction) (any, error) {
+		if skip > 0 {
+			skip--
+			return 0, nil
+		}
+		if !m.enabled {
+			return 0, nil
+		}
+		x, _ := strconv.Atoi(instruction.Args[0])
+		y, _ := strconv.Atoi(instruction.Args[1])
+		m.total.Add(x + y)
+		return x + y, m.total.Err()
+	}
+	set := instructionSet{
+		InstructionTypeMul:  part2Instructions[InstructionTypeMul],
+		InstructionTypeDo:   part2Instructions[InstructionTypeDo],
+		InstructionTypeDont: part2Instructions[InstructionTypeDont],
+		"add":               {arity: 2, apply: applyAdd},
+		"skip": {arity: 1, apply: func(m *machine, instruction Instruction) (any, error) {
+			skip, _ = strconv.Atoi(instruction.Args[0])
+			return nil, nil
+		}},
+	}
+
+	total, err := set.run("mul(2,3)add(4,5)skip(1)add(1,1)xadd(10,20)don't()add(7,7)do()add(1,2", checked.Strict)
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	// 6 + 9, add(1,1) skipped, 30, add(7,7) disabled, add(1,2 unterminated
+	result, err := total.Int()
+	if err != nil || result != 45 {
+		t.Errorf("run() = %d, %v, expected 45", result, err)
+	}
+}
+
+func TestInstructionNamesLongestFirst(t *testing.T) {
+	// "don't" ends in "t", so a table with both must try "don't" first
+	set := instructionSet{
+		InstructionTypeDont: part2Instructions[InstructionTypeDont],
+		"t":                 {arity: 0, apply: applyEnable(true)},
+	}
+
+	instructions := set.parse("don't()t()")
+	if len(instructions) != 2 || instructions[0].Type != InstructionTypeDont || instructions[1].Type != "t" {
+		t.Errorf("parse() = %+v, expected don't() then t()", instructions)
+	}
+}
@@ -0,0 +1,123 @@
+package day03
+
+import "fmt"
+
+// TokenKind classifies a token of corrupted memory
+type TokenKind int
+
+const (
+	TokenWord   TokenKind = iota // letters, apostrophes and underscores, e.g. "xmul" or "don't"
+	TokenNumber                  // a run of ASCII digits
+	TokenLParen                  // (
+	TokenRParen                  // )
+	TokenComma                   // ,
+	TokenSpace                   // a single space, tab or line break
+	TokenSign                    // + or -
+	TokenOther                   // any other single byte
+)
+
+func (k TokenKind) String() string {
+	switch k {
+	case TokenWord:
+		return "word"
+	case TokenNumber:
+		return "number"
+	case TokenLParen:
+		return "("
+	case TokenRParen:
+		return ")"
+	case TokenComma:
+		return ","
+	case TokenSpace:
+		return "space"
+	case TokenSign:
+		return "sign"
+	case TokenOther:
+		return "other"
+	default:
+		return fmt.Sprintf("TokenKind(%d)", int(k))
+	}
+}
+
+// Token is a lexeme and its byte offset in the input
+type Token struct {
+	Kind     TokenKind
+	Position int
+	Text     string
+}
+
+// End returns the offset just past the token
+func (t Token) End() int {
+	return t.Position + len(t.Text)
+}
+
+// lexer splits corrupted memory into tokens in a single pass
+type lexer struct {
+	input string
+	pos   int
+}
+
+// next returns the next token, or false at the end of the input
+func (l *lexer) next() (Token, bool) {
+	if l.pos >= len(l.input) {
+		return Token{}, false
+	}
+
+	start := l.pos
+	c := l.input[l.pos]
+	l.pos++
+
+	var kind TokenKind
+	switch {
+	case isWordByte(c):
+		kind = TokenWord
+		for l.pos < len(l.input) && isWordByte(l.input[l.pos]) {
+			l.pos++
+		}
+	case isDigit(c):
+		kind = TokenNumber
+		for l.pos < len(l.input) && isDigit(l.input[l.pos]) {
+			l.pos++
+		}
+	case c == '(':
+		kind = TokenLParen
+	case c == ')':
+		kind = TokenRParen
+	case c == ',':
+		kind = TokenComma
+	case isSpace(c):
+		kind = TokenSpace
+	case c == '+' || c == '-':
+		kind = TokenSign
+	default:
+		kind = TokenOther
+	}
+
+	return Token{Kind: kind, Position: start, Text: l.input[start:l.pos]}, true
+}
+
+// Tokenize splits input into tokens. Every byte belongs to exactly one token,
+// so the token texts concatenate back to the input.
+func Tokenize(input string) []Token {
+	var tokens []Token
+	l := lexer{input: input}
+	for {
+		token, ok := l.next()
+		if !ok {
+			return tokens
+		}
+		tokens = append(tokens, token)
+	}
+}
+
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '\'' || c == '_'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,54 @@
+package day03
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	input := "xmul(2,4)%don't()"
+	expected := []Token{
+		{Kind: TokenWord, Position: 0, Text: "xmul"},
+		{Kind: TokenLParen, Position: 4, Text: "("},
+		{Kind: TokenNumber, Position: 5, Text: "2"},
+		{Kind: TokenComma, Position: 6, Text: ","},
+		{Kind: TokenNumber, Position: 7, Text: "4"},
+		{Kind: TokenRParen, Position: 8, Text: ")"},
+		{Kind: TokenOther, Position: 9, Text: "%"},
+		{Kind: TokenWord, Position: 10, Text: "don't"},
+		{Kind: TokenLParen, Position: 15, Text: "("},
+		{Kind: TokenRParen, Position: 16, Text: ")"},
+	}
+
+	tokens := Tokenize(input)
+	if len(tokens) != len(expected) {
+		t.Fatalf("Tokenize(%q) returned %d tokens, expected %d: %v", input, len(tokens), len(expected), tokens)
+	}
+	for i, token := range tokens {
+		if token != expected[i] {
+			t.Errorf("token %d: expected %+v, got %+v", i, expected[i], token)
+		}
+	}
+}
+
+func TestTokenizeCoversInput(t *testing.T) {
+	inputs := []string{
+		"",
+		"xmul(2,4)&mul[3,7]!^don't()_mul(5,5)+mul(32,64](mul(11,8)undo()?mul(8,5))",
+		"mul ( 2 , 4 )\n\tmul(123456789012345678901234567890,1)",
+		"\x00\xff日本mul(1,2)",
+	}
+
+	for _, input := range inputs {
+		var rebuilt strings.Builder
+		for _, token := range Tokenize(input) {
+			if token.Position != rebuilt.Len() {
+				t.Errorf("Tokenize(%q): token %+v starts at %d, expected %d", input, token, token.Position, rebuilt.Len())
+			}
+			rebuilt.WriteString(token.Text)
+		}
+		if rebuilt.String() != input {
+			t.Errorf("Tokenize(%q) tokens rebuild %q", input, rebuilt.String())
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package day03
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"advent-of-code-2024/internal/checked"
+)
+
+// maxStreamNumber is the most digits SolveStream keeps of a number, not counting
+// leading zeros. Real dumps come nowhere near it, and without a limit a single
+// token could grow as large as the input. A longer number cannot fit in an int,
+// so the rest of its digits are read but not kept: as noise it changes nothing,
+// and as an argument it still overflows, just as it does in memory.
+const maxStreamNumber = 1 << 16
+
+// StreamResult holds both puzzle answers computed by SolveStream
+type StreamResult struct {
+	Instructions int // mul, do() and don't() instructions found
+	Part1        int
+	Part2        int
+}
+
+// SolveStream computes both parts in a single pass over r. Memory stays constant
+// however large the input is: the lexer reads through a fixed buffer and keeps
+// only the end of each word, which is all an instruction name can use, so an
+// instruction split across two reads is found just the same.
+func SolveStream(r io.Reader) (StreamResult, error) {
+	longestName := len(part2Instructions.names()[0])
+	l := newStreamLexer(r, longestName)
+	p := newParser(part2Instructions, l, Grammar{})
+
+	// Both parts see the same instructions; part 1 just has no do() or don't()
+	part1 := &machine{enabled: true, total: new(checked.Sum)}
+	part2 := &machine{enabled: true, total: new(checked.Sum)}
+
+	var result StreamResult
+	for {
+		instruction, ok := p.next()
+		if !ok {
+			break
+		}
+		result.Instructions++
+
+		if op, ok := part1Instructions[instruction.Type]; ok {
+			if _, err := op.apply(part1, instruction); err != nil {
+				return StreamResult{}, err
+			}
+		}
+		if _, err := part2Instructions[instruction.Type].apply(part2, instruction); err != nil {
+			return StreamResult{}, err
+		}
+	}
+	if l.err != nil {
+		return StreamResult{}, l.err
+	}
+
+	var err error
+	if result.Part1, err = part1.total.Int(); err != nil {
+		return StreamResult{}, err
+	}
+	if result.Part2, err = part2.total.Int(); err != nil {
+		return StreamResult{}, err
+	}
+	return result, nil
+}
+
+// streamLexer is lexer for an io.Reader. Word tokens keep only their last
+// maxWord bytes, with Position moved up to match, and number tokens at most
+// maxStreamNumber digits after their leading zeros.
+type streamLexer struct {
+	reader  *bufio.Reader
+	maxWord int
+	pos     int
+	text    []byte // reused between tokens
+	err     error
+}
+
+func newStreamLexer(r io.Reader, maxWord int) *streamLexer {
+	return &streamLexer{reader: bufio.NewReader(r), maxWord: maxWord}
+}
+
+// next returns the next token, or false at the end of the input or on a read
+// error, which is left in err
+func (l *streamLexer) next() (Token, bool) {
+	c, ok := l.readByte()
+	if !ok {
+		return Token{}, false
+	}
+
+	start := l.pos - 1
+	l.text = append(l.text[:0], c)
+
+	var kind TokenKind
+	switch {
+	case isWordByte(c):
+		kind = TokenWord
+		for l.peekIs(isWordByte) {
+			c, _ = l.readByte()
+			if len(l.text) == l.maxWord {
+				copy(l.text, l.text[1:])
+				l.text = l.text[:len(l.text)-1]
+				start++
+			}
+			l.text = append(l.text, c)
+		}
+	case isDigit(c):
+		kind = TokenNumber
+		for l.peekIs(isDigit) {
+			c, _ = l.readByte()
+			if len(l.text) == maxStreamNumber {
+				// Leading zeros do not change the value, so drop them before
+				// giving up on the rest of the digits
+				l.text = bytes.TrimLeft(l.text, "0")
+				if len(l.text) == maxStreamNumber {
+					continue
+				}
+			}
+			l.text = append(l.text, c)
+		}
+	case c == '(':
+		kind = TokenLParen
+	case c == ')':
+		kind = TokenRParen
+	case c == ',':
+		kind = TokenComma
+	case isSpace(c):
+		kind = TokenSpace
+	case c == '+' || c == '-':
+		kind = TokenSign
+	default:
+		kind = TokenOther
+	}
+
+	return Token{Kind: kind, Position: start, Text: string(l.text)}, true
+}
+
+func (l *streamLexer) readByte() (byte, bool) {
+	if l.err != nil {
+		return 0, false
+	}
+
+	c, err := l.reader.ReadByte()
+	if err != nil {
+		if err != io.EOF {
+			l.err = err
+		}
+		return 0, false
+	}
+	l.pos++
+	return c, true
+}
+
+// peekIs reports whether the next byte satisfies class, without consuming it
+func (l *streamLexer) peekIs(class func(byte) bool) bool {
+	if l.err != nil {
+		return false
+	}
+
+	next, err := l.reader.Peek(1)
+	if err != nil {
+		if err != io.EOF {
+			l.err = err
+		}
+		return false
+	}
+	return class(next[0])
+}
@@ -0,0 +1,109 @@
+package day03
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"advent-of-code-2024/internal/checked"
+	"advent-of-code-2024/internal/gen"
+)
+
+func TestSolveStream(t *testing.T) {
+	file, err := os.Open("example-part2-input.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	result, err := SolveStream(file)
+	if err != nil {
+		t.Fatalf("SolveStream() error = %v", err)
+	}
+
+	expected := StreamResult{Instructions: 6, Part1: 161, Part2: 48}
+	if result != expected {
+		t.Errorf("SolveStream() = %+v, expected %+v", result, expected)
+	}
+}
+
+func TestSolveStreamMatchesSolvers(t *testing.T) {
+	inputs := map[string]string{
+		"long words":            strings.Repeat("x", 10000) + "mul(2,3)" + strings.Repeat("un", 5000) + "don't()mul(4,5)",
+		"long number":           "mul(" + strings.Repeat("0", 5000) + "7,6)",
+		"digits past the limit": "mul(2,3)" + strings.Repeat("9", maxStreamNumber+10) + "mul(4,5)",
+		"zeros past the limit":  "mul(" + strings.Repeat("0", maxStreamNumber+10) + "7,6)",
+		"unfinished":            "mul(2,3)don't()mul(4,5)do(",
+		"too many":              "mul(1,2,3)mul(4,5)",
+	}
+	for _, seed := range []int64{1, 2, 3} {
+		input, err := gen.String(3, 200, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs[fmt.Sprintf("generated %d", seed)] = input
+	}
+	for _, name := range []string{"example-input.txt", "example-part2-input.txt", "puzzle-input.txt"} {
+		content, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs[name] = string(content)
+	}
+
+	// Reading a byte or half a buffer at a time splits instructions across reads
+	readers := map[string]func(io.Reader) io.Reader{
+		"whole":    func(r io.Reader) io.Reader { return r },
+		"one byte": iotest.OneByteReader,
+		"half":     iotest.HalfReader,
+	}
+
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			part1, err := processCorruptedMemory(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			part2, err := processWithConditionals(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := StreamResult{Instructions: len(findAllInstructions(input)), Part1: part1, Part2: part2}
+
+			for readerName, wrap := range readers {
+				result, err := SolveStream(wrap(strings.NewReader(input)))
+				if err != nil {
+					t.Fatalf("%s: SolveStream() error = %v", readerName, err)
+				}
+				if result != expected {
+					t.Errorf("%s: SolveStream() = %+v, expected %+v", readerName, result, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestSolveStreamErrors(t *testing.T) {
+	if _, err := SolveStream(strings.NewReader("mul(4294967296,4294967296)")); !errors.Is(err, checked.ErrOverflow) {
+		t.Errorf("SolveStream() error = %v, expected ErrOverflow", err)
+	}
+
+	// An argument too long to keep overflows, as it does for the in-memory solver
+	long := "mul(" + strings.Repeat("1", maxStreamNumber+1) + ",2)"
+	if _, err := processCorruptedMemory(long); !errors.Is(err, checked.ErrOverflow) {
+		t.Errorf("processCorruptedMemory() error = %v, expected ErrOverflow", err)
+	}
+	if _, err := SolveStream(strings.NewReader(long)); !errors.Is(err, checked.ErrOverflow) {
+		t.Errorf("SolveStream() error = %v, expected ErrOverflow", err)
+	}
+
+	readErr := errors.New("disk on fire")
+	r := io.MultiReader(strings.NewReader("mul(2,3)"), iotest.ErrReader(readErr))
+	if _, err := SolveStream(r); !errors.Is(err, readErr) {
+		t.Errorf("SolveStream() error = %v, expected %v", err, readErr)
+	}
+}
@@ -19,7 +19,11 @@ package day04
 
 import (
 	"bufio"
+	"fmt"
+	"io"
 	"os"
+
+	"advent-of-code-2024/internal/trace"
 )
 
 func parseGrid(filename string) ([][]rune, error) {
@@ -29,13 +33,28 @@ func parseGrid(filename string) ([][]rune, error) {
 	}
 	defer file.Close()
 
+	return parseGridReader(file)
+}
+
+// parseGridReader reads a rectangular letter grid from r, one row per non-empty line
+func parseGridReader(r io.Reader) ([][]rune, error) {
+	return readGrid(r, false)
+}
+
+// readGrid reads a letter grid from r, one row per non-empty line. Unless ragged
+// is set, every row must be as long as the first.
+func readGrid(r io.Reader, ragged bool) ([][]rune, error) {
 	var grid [][]rune
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(r)
 
 	for scanner.Scan() {
 		line := scanner.Text()
 		if len(line) > 0 {
-			grid = append(grid, []rune(line))
+			row := []rune(line)
+			if !ragged && len(grid) > 0 && len(row) != len(grid[0]) {
+				return nil, fmt.Errorf("row %d has %d columns, expected %d", len(grid), len(row), len(grid[0]))
+			}
+			grid = append(grid, row)
 		}
 	}
 
@@ -47,56 +66,49 @@ func parseGrid(filename string) ([][]rune, error) {
 }
 
 func isValidPosition(grid [][]rune, row, col int) bool {
-	if len(grid) == 0 {
-		return false
-	}
-	return row >= 0 && row < len(grid) && col >= 0 && col < len(grid[0])
+	_, _, ok := Topology{}.cell(grid, row, col)
+	return ok
 }
 
 func checkStringInDirection(grid [][]rune, row, col, deltaRow, deltaCol int, target string) bool {
-	for i, char := range target {
-		newRow := row + i*deltaRow
-		newCol := col + i*deltaCol
-
-		if !isValidPosition(grid, newRow, newCol) {
-			return false
-		}
-
-		if grid[newRow][newCol] != char {
-			return false
-		}
-	}
-	return true
+	return Topology{}.readsAt(grid, row, col, deltaRow, deltaCol, target)
 }
 
 func findXMAS(grid [][]rune) int {
-	if len(grid) == 0 {
-		return 0
-	}
+	return findXMASOn(grid, Topology{})
+}
 
-	count := 0
+// findXMASOn counts XMAS on a grid whose edges behave as topology says
+func findXMASOn(grid [][]rune, topology Topology) int {
 	target := "XMAS"
 
-	// All 8 directions: right, down, diagonal down-right, diagonal down-left,
-	// left, up, diagonal up-left, diagonal up-right
-	directions := [][2]int{
-		{0, 1},   // right
-		{1, 0},   // down
-		{1, 1},   // diagonal down-right
-		{1, -1},  // diagonal down-left
-		{0, -1},  // left
-		{-1, 0},  // up
-		{-1, -1}, // diagonal up-left
-		{-1, 1},  // diagonal up-right
+	var match func(row, col int, direction Direction)
+	if trace.Enabled() {
+		match = func(row, col int, direction Direction) {
+			trace.Emit("match", fmt.Sprintf("%s at (%d,%d) reading %s", target, row, col, direction),
+				map[string]any{"word": target, "row": row, "col": col, "direction": direction.String()})
+		}
 	}
 
-	// Check each position in the grid
-	for row := 0; row < len(grid); row++ {
-		for col := 0; col < len(grid[0]); col++ {
+	return countWordInRows(grid, topology, target, 0, len(grid), match)
+}
+
+// countWordInRows counts occurrences of target that start in rows [from, to),
+// calling match for each one if it is set
+func countWordInRows(grid [][]rune, topology Topology, target string, from, to int, match func(row, col int, direction Direction)) int {
+	count := 0
+
+	// Check each position in the rows
+	for row := from; row < to; row++ {
+		for col := 0; col < len(grid[row]); col++ {
 			// Check each direction from this position
-			for _, dir := range directions {
-				if checkStringInDirection(grid, row, col, dir[0], dir[1], target) {
+			for _, direction := range Directions {
+				deltaRow, deltaCol := direction.Delta()
+				if topology.readsAt(grid, row, col, deltaRow, deltaCol, target) {
 					count++
+					if match != nil {
+						match(row, col, direction)
+					}
 				}
 			}
 		}
@@ -114,46 +126,40 @@ func SolvePart1(filename string) (int, error) {
 		return 0, err
 	}
 
-	return findXMAS(grid), nil
+	return findXMASParallel(grid), nil
 }
 
+// xmasOrientations are the four ways of writing the two MAS of an X-MAS
+var xmasOrientations = xmasStencil.Orientations()
+
 func checkXPattern(grid [][]rune, centerRow, centerCol int) bool {
-	// Check if center position is valid and contains 'A'
-	if !isValidPosition(grid, centerRow, centerCol) || grid[centerRow][centerCol] != 'A' {
-		return false
+	// The stencil is 3x3, so its top-left corner is diagonally above the A
+	for _, orientation := range xmasOrientations {
+		if orientation.matchesAt(grid, centerRow-1, centerCol-1, Topology{}) {
+			return true
+		}
 	}
-
-	// Check both diagonals using checkStringInDirection
-	// Diagonal 1: top-left to bottom-right (should be "MAS" or "SAM")
-	topLeftRow, topLeftCol := centerRow-1, centerCol-1
-	diagonal1Valid := checkStringInDirection(grid, topLeftRow, topLeftCol, 1, 1, "MAS") ||
-		checkStringInDirection(grid, topLeftRow, topLeftCol, 1, 1, "SAM")
-
-	// Diagonal 2: top-right to bottom-left (should be "MAS" or "SAM")
-	topRightRow, topRightCol := centerRow-1, centerCol+1
-	diagonal2Valid := checkStringInDirection(grid, topRightRow, topRightCol, 1, -1, "MAS") ||
-		checkStringInDirection(grid, topRightRow, topRightCol, 1, -1, "SAM")
-
-	return diagonal1Valid && diagonal2Valid
+	return false
 }
 
 func findXMASPattern(grid [][]rune) int {
-	if len(grid) < 3 {
-		return 0
-	}
+	return findXMASPatternOn(grid, Topology{})
+}
 
-	count := 0
+// findXMASPatternOn counts X-MAS on a grid whose edges behave as topology says
+func findXMASPatternOn(grid [][]rune, topology Topology) int {
+	matches := FindStencilOn(grid, xmasStencil, topology)
 
-	// Check each possible center position (must have room for the X pattern)
-	for row := 1; row < len(grid)-1; row++ {
-		for col := 1; col < len(grid[0])-1; col++ {
-			if checkXPattern(grid, row, col) {
-				count++
-			}
+	if trace.Enabled() {
+		for _, match := range matches {
+			// On a wrapping grid the centre may be across an edge from the corner
+			row, col, _ := topology.cell(grid, match.Row+1, match.Col+1)
+			trace.Emit("match", fmt.Sprintf("X-MAS centred at (%d,%d)", row, col),
+				map[string]any{"row": row, "col": col})
 		}
 	}
 
-	return count
+	return len(matches)
 }
 
 // SolvePart2 solves part 2 of the Day 4 puzzle by finding X-MAS patterns.
@@ -165,5 +171,5 @@ func SolvePart2(filename string) (int, error) {
 		return 0, err
 	}
 
-	return findXMASPattern(grid), nil
+	return findXMASPatternParallel(grid), nil
 }
@@ -0,0 +1,69 @@
+package day04
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"advent-of-code-2024/internal/gen"
+)
+
+// benchmarkGridSize is the side of the generated grid. At 10k x 10k the grid
+// takes about 400MB as runes.
+const benchmarkGridSize = 10000
+
+func benchmarkGrid(b *testing.B) [][]rune {
+	if testing.Short() {
+		b.Skip("skipping 10k x 10k grid in short mode")
+	}
+
+	input, err := gen.String(4, benchmarkGridSize, 1)
+	if err != nil {
+		b.Fatal(err)
+	}
+	grid, err := parseGridReader(strings.NewReader(input))
+	if err != nil {
+		b.Fatal(err)
+	}
+	return grid
+}
+
+// workerCounts are the worker counts the banded benchmarks compare, from one
+// band up to one per CPU
+func workerCounts() []int {
+	counts := []int{1}
+	for n := 2; n < runtime.NumCPU(); n *= 2 {
+		counts = append(counts, n)
+	}
+	if runtime.NumCPU() > 1 {
+		counts = append(counts, runtime.NumCPU())
+	}
+	return counts
+}
+
+// Benchmark part 1 on a 10k x 10k grid with one band per worker
+func BenchmarkFindXMASWorkerCounts(b *testing.B) {
+	grid := benchmarkGrid(b)
+
+	for _, numWorkers := range workerCounts() {
+		b.Run(fmt.Sprintf("%d_workers", numWorkers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				findXMASParallelWithWorkers(grid, numWorkers)
+			}
+		})
+	}
+}
+
+// Benchmark part 2 on a 10k x 10k grid with one band per worker
+func BenchmarkFindXMASPatternWorkerCounts(b *testing.B) {
+	grid := benchmarkGrid(b)
+
+	for _, numWorkers := range workerCounts() {
+		b.Run(fmt.Sprintf("%d_workers", numWorkers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				findXMASPatternParallelWithWorkers(grid, numWorkers)
+			}
+		})
+	}
+}
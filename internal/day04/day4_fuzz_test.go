@@ -0,0 +1,54 @@
+package day04
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func FuzzParseGrid(f *testing.F) {
+	example, err := os.ReadFile("example-input.txt")
+	if err != nil {
+		f.Fatalf("failed to read example input: %v", err)
+	}
+	f.Add(string(example))
+	f.Add("")
+	f.Add("XMAS\nXM")
+	f.Add("X\nM\nA\nS")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		grid, err := parseGridReader(strings.NewReader(data))
+		if err != nil {
+			return
+		}
+		for row := range grid {
+			if len(grid[row]) != len(grid[0]) {
+				t.Fatalf("row %d has %d columns, row 0 has %d", row, len(grid[row]), len(grid[0]))
+			}
+		}
+	})
+}
+
+func FuzzFindXMAS(f *testing.F) {
+	example, err := os.ReadFile("example-input.txt")
+	if err != nil {
+		f.Fatalf("failed to read example input: %v", err)
+	}
+	f.Add(string(example))
+	f.Add("XMAS")
+	f.Add("M.S\n.A.\nM.S")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		grid, err := parseGridReader(strings.NewReader(data))
+		if err != nil {
+			return
+		}
+
+		if count := findXMAS(grid); count > 8*strings.Count(data, "X") {
+			t.Fatalf("findXMAS() = %d with only %d X cells", count, strings.Count(data, "X"))
+		}
+		if count := findXMASPattern(grid); count > strings.Count(data, "A") {
+			t.Fatalf("findXMASPattern() = %d with only %d A cells", count, strings.Count(data, "A"))
+		}
+	})
+}
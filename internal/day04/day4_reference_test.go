@@ -0,0 +1,98 @@
+package day04
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"advent-of-code-2024/internal/difftest"
+)
+
+func referenceGrid(input string) ([]string, error) {
+	var rows []string
+	for _, line := range strings.Split(input, "\n") {
+		if line == "" {
+			continue
+		}
+		if len(rows) > 0 && len([]rune(line)) != len([]rune(rows[0])) {
+			return nil, fmt.Errorf("ragged row %q", line)
+		}
+		rows = append(rows, line)
+	}
+	return rows, nil
+}
+
+// referenceAt returns the letter at row, col or 0 when outside the grid
+func referenceAt(rows [][]rune, row, col int) rune {
+	if row < 0 || row >= len(rows) || col < 0 || col >= len(rows[row]) {
+		return 0
+	}
+	return rows[row][col]
+}
+
+// referencePart1 reads the four letters in every direction from every cell
+func referencePart1(input string) (int, error) {
+	lines, err := referenceGrid(input)
+	if err != nil {
+		return 0, err
+	}
+	rows := make([][]rune, len(lines))
+	for i, line := range lines {
+		rows[i] = []rune(line)
+	}
+
+	count := 0
+	for row := range rows {
+		for col := range rows[row] {
+			for dr := -1; dr <= 1; dr++ {
+				for dc := -1; dc <= 1; dc++ {
+					if dr == 0 && dc == 0 {
+						continue
+					}
+					word := string([]rune{
+						referenceAt(rows, row, col),
+						referenceAt(rows, row+dr, col+dc),
+						referenceAt(rows, row+2*dr, col+2*dc),
+						referenceAt(rows, row+3*dr, col+3*dc),
+					})
+					if word == "XMAS" {
+						count++
+					}
+				}
+			}
+		}
+	}
+	return count, nil
+}
+
+// referencePart2 reads both diagonals of every 3x3 window
+func referencePart2(input string) (int, error) {
+	lines, err := referenceGrid(input)
+	if err != nil {
+		return 0, err
+	}
+	rows := make([][]rune, len(lines))
+	for i, line := range lines {
+		rows[i] = []rune(line)
+	}
+
+	isMAS := func(word string) bool { return word == "MAS" || word == "SAM" }
+
+	count := 0
+	for row := range rows {
+		for col := range rows[row] {
+			first := string([]rune{referenceAt(rows, row, col), referenceAt(rows, row+1, col+1), referenceAt(rows, row+2, col+2)})
+			second := string([]rune{referenceAt(rows, row, col+2), referenceAt(rows, row+1, col+1), referenceAt(rows, row+2, col)})
+			if isMAS(first) && isMAS(second) {
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+func TestDifferential(t *testing.T) {
+	difftest.Run(t, 4, []int{1, 4, 25},
+		difftest.Part{Solve: SolvePart1, Reference: referencePart1},
+		difftest.Part{Solve: SolvePart2, Reference: referencePart2})
+}
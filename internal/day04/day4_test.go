@@ -1,7 +1,10 @@
 package day04
 
 import (
+	"strings"
 	"testing"
+
+	"advent-of-code-2024/internal/trace"
 )
 
 func TestParseGrid(t *testing.T) {
@@ -43,6 +46,13 @@ func TestParseGridNonExistentFile(t *testing.T) {
 	}
 }
 
+func TestParseGridRaggedRows(t *testing.T) {
+	_, err := parseGridReader(strings.NewReader("XMAS\nXM\nXMAS"))
+	if err == nil {
+		t.Error("Expected error for rows of different lengths, got nil")
+	}
+}
+
 func TestIsValidPosition(t *testing.T) {
 	grid := [][]rune{
 		{'A', 'B', 'C'},
@@ -271,3 +281,28 @@ func TestSolvePart2(t *testing.T) {
 		t.Errorf("SolvePart2() = %d, expected %d", result, expected)
 	}
 }
+
+func TestTraceEvents(t *testing.T) {
+	var events []trace.Event
+	trace.SetHook(func(e trace.Event) { events = append(events, e) })
+	defer trace.SetHook(nil)
+
+	grid := [][]rune{
+		{'X', 'M', 'A', 'S'},
+		{'S', 'A', 'M', 'X'},
+	}
+	findXMAS(grid)
+
+	expected := []string{
+		"XMAS at (0,0) reading right",
+		"XMAS at (1,3) reading left",
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, got %d", len(expected), len(events))
+	}
+	for i, message := range expected {
+		if events[i].Message != message {
+			t.Errorf("events[%d].Message = %q, expected %q", i, events[i].Message, message)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package day04
+
+import (
+	"runtime"
+	"sync"
+
+	"advent-of-code-2024/internal/trace"
+)
+
+// parallelMinCells is the grid size below which splitting into bands costs
+// more than it saves, so the puzzle's own grid is searched serially
+const parallelMinCells = 1 << 16
+
+// band is a run of rows one worker owns. The worker searches a slice of the
+// grid that reaches margin rows past either end, so it sees every match that
+// starts in its rows even if the match crosses into a neighbouring band, and
+// counts only those that start in its own rows, so each match is counted once.
+type band struct {
+	start, end int
+}
+
+// splitBands divides rows into at most numWorkers bands of nearly equal height
+func splitBands(rows, numWorkers int) []band {
+	numWorkers = max(min(numWorkers, rows), 1)
+	height := (rows + numWorkers - 1) / numWorkers
+
+	var bands []band
+	for start := 0; start < rows; start += height {
+		bands = append(bands, band{start: start, end: min(start+height, rows)})
+	}
+	return bands
+}
+
+// countInBands runs count on each band in parallel and adds up the results.
+// count receives the band with its margins and the rows it owns within it.
+func countInBands(grid [][]rune, margin, numWorkers int, count func(rows [][]rune, from, to int) int) int {
+	bands := splitBands(len(grid), numWorkers)
+	results := make(chan int, len(bands))
+
+	var wg sync.WaitGroup
+	for _, b := range bands {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lo, hi := max(b.start-margin, 0), min(b.end+margin, len(grid))
+			results <- count(grid[lo:hi], b.start-lo, b.end-lo)
+		}()
+	}
+
+	wg.Wait()
+	close(results)
+
+	total := 0
+	for result := range results {
+		total += result
+	}
+	return total
+}
+
+// searchSerially reports whether a grid is better searched on one goroutine:
+// it is small, or tracing wants its matches in order
+func searchSerially(grid [][]rune) bool {
+	return trace.Enabled() || len(grid) == 0 || len(grid)*len(grid[0]) < parallelMinCells
+}
+
+// findXMASParallel counts XMAS like findXMAS, across one band per CPU
+func findXMASParallel(grid [][]rune) int {
+	return findXMASParallelWithWorkers(grid, runtime.NumCPU())
+}
+
+// findXMASParallelWithWorkers counts XMAS across numWorkers bands of rows.
+// XMAS can read up or down from its first letter, so each band reaches three
+// rows into its neighbours on both sides.
+func findXMASParallelWithWorkers(grid [][]rune, numWorkers int) int {
+	if searchSerially(grid) {
+		return findXMAS(grid)
+	}
+
+	target := "XMAS"
+	margin := len(target) - 1
+	return countInBands(grid, margin, numWorkers, func(rows [][]rune, from, to int) int {
+		return countWordInRows(rows, Topology{}, target, from, to, nil)
+	})
+}
+
+// findXMASPatternParallel counts X-MAS like findXMASPattern, across one band
+// per CPU
+func findXMASPatternParallel(grid [][]rune) int {
+	return findXMASPatternParallelWithWorkers(grid, runtime.NumCPU())
+}
+
+// findXMASPatternParallelWithWorkers counts X-MAS across numWorkers bands of
+// rows. A match belongs to the band holding its top row, and the stencil is
+// three rows tall, so bands reach two rows into their neighbours.
+func findXMASPatternParallelWithWorkers(grid [][]rune, numWorkers int) int {
+	if searchSerially(grid) {
+		return findXMASPattern(grid)
+	}
+
+	height, width := xmasStencil.Size()
+	margin := max(height, width) - 1
+	return countInBands(grid, margin, numWorkers, func(rows [][]rune, from, to int) int {
+		return len(findStencilInRows(rows, xmasOrientations, Topology{}, from, to, nil))
+	})
+}
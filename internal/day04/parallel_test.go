@@ -0,0 +1,88 @@
+package day04
+
+import (
+	"strings"
+	"testing"
+
+	"advent-of-code-2024/internal/gen"
+)
+
+func TestSplitBands(t *testing.T) {
+	tests := []struct {
+		rows, numWorkers int
+		expected         []band
+	}{
+		{10, 3, []band{{0, 4}, {4, 8}, {8, 10}}},
+		{3, 8, []band{{0, 1}, {1, 2}, {2, 3}}},
+		{5, 0, []band{{0, 5}}},
+		{0, 4, nil},
+	}
+
+	for _, tt := range tests {
+		bands := splitBands(tt.rows, tt.numWorkers)
+		if len(bands) != len(tt.expected) {
+			t.Errorf("splitBands(%d, %d) = %v, expected %v", tt.rows, tt.numWorkers, bands, tt.expected)
+			continue
+		}
+		for i := range bands {
+			if bands[i] != tt.expected[i] {
+				t.Errorf("splitBands(%d, %d) = %v, expected %v", tt.rows, tt.numWorkers, bands, tt.expected)
+				break
+			}
+		}
+	}
+}
+
+func TestCountInBandsMatchesSerial(t *testing.T) {
+	// Bands of one or two rows put most matches across a boundary
+	input, err := gen.String(4, 40, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grid, err := parseGridReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	words, patterns := findXMAS(grid), findXMASPattern(grid)
+	for _, numWorkers := range []int{1, 2, 3, 7, 20, 40, 100} {
+		result := countInBands(grid, 3, numWorkers, func(rows [][]rune, from, to int) int {
+			return countWordInRows(rows, Topology{}, "XMAS", from, to, nil)
+		})
+		if result != words {
+			t.Errorf("XMAS across %d bands = %d, expected %d", numWorkers, result, words)
+		}
+
+		result = countInBands(grid, 2, numWorkers, func(rows [][]rune, from, to int) int {
+			return len(findStencilInRows(rows, xmasOrientations, Topology{}, from, to, nil))
+		})
+		if result != patterns {
+			t.Errorf("X-MAS across %d bands = %d, expected %d", numWorkers, result, patterns)
+		}
+	}
+}
+
+func TestFindXMASParallel(t *testing.T) {
+	// Large enough to be split into bands rather than searched serially
+	input, err := gen.String(4, 300, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grid, err := parseGridReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if searchSerially(grid) {
+		t.Fatal("test grid is below the parallel threshold")
+	}
+
+	words, patterns := findXMAS(grid), findXMASPattern(grid)
+	for _, numWorkers := range []int{1, 4, 16} {
+		if result := findXMASParallelWithWorkers(grid, numWorkers); result != words {
+			t.Errorf("findXMASParallelWithWorkers(%d) = %d, expected %d", numWorkers, result, words)
+		}
+		if result := findXMASPatternParallelWithWorkers(grid, numWorkers); result != patterns {
+			t.Errorf("findXMASPatternParallelWithWorkers(%d) = %d, expected %d", numWorkers, result, patterns)
+		}
+	}
+}
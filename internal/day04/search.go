@@ -0,0 +1,425 @@
+package day04
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Direction is one of the eight ways a word can be read through the grid
+type Direction int
+
+// The first four directions read each row, column and diagonal forwards; the
+// last four read them backwards, in the same order
+const (
+	Right Direction = iota
+	Down
+	DownRight
+	DownLeft
+	Left
+	Up
+	UpLeft
+	UpRight
+)
+
+// Directions lists all eight directions in order
+var Directions = []Direction{Right, Down, DownRight, DownLeft, Left, Up, UpLeft, UpRight}
+
+var directionDeltas = [...][2]int{
+	Right:     {0, 1},
+	Down:      {1, 0},
+	DownRight: {1, 1},
+	DownLeft:  {1, -1},
+	Left:      {0, -1},
+	Up:        {-1, 0},
+	UpLeft:    {-1, -1},
+	UpRight:   {-1, 1},
+}
+
+var directionNames = [...]string{
+	Right:     "right",
+	Down:      "down",
+	DownRight: "down-right",
+	DownLeft:  "down-left",
+	Left:      "left",
+	Up:        "up",
+	UpLeft:    "up-left",
+	UpRight:   "up-right",
+}
+
+// Delta returns the row and column step of d
+func (d Direction) Delta() (int, int) {
+	return directionDeltas[d][0], directionDeltas[d][1]
+}
+
+// Reverse returns the direction reading the same line the other way
+func (d Direction) Reverse() Direction {
+	return (d + 4) % 8
+}
+
+func (d Direction) String() string {
+	if d < 0 || int(d) >= len(directionNames) {
+		return fmt.Sprintf("Direction(%d)", int(d))
+	}
+	return directionNames[d]
+}
+
+// MarshalText writes the direction by name, so JSON shows "down-left" rather than 3
+func (d Direction) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText reads a direction name
+func (d *Direction) UnmarshalText(text []byte) error {
+	for i, name := range directionNames {
+		if name == string(text) {
+			*d = Direction(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown direction %q", text)
+}
+
+// Match is one occurrence of a word: it starts at Row, Col and reads in Direction
+type Match struct {
+	Word      string    `json:"word"`
+	Row       int       `json:"row"`
+	Col       int       `json:"col"`
+	Direction Direction `json:"direction"`
+}
+
+// WordSearch finds every word of a dictionary in a grid at once. The words go
+// into an Aho-Corasick automaton, which then reads each row, column and diagonal
+// forwards and backwards a single time, however many words there are.
+type WordSearch struct {
+	words     []string
+	automaton *automaton
+}
+
+// NewWordSearch builds a search for words. Duplicates are searched once.
+func NewWordSearch(words []string) (*WordSearch, error) {
+	if len(words) == 0 {
+		return nil, fmt.Errorf("no words to search for")
+	}
+
+	var unique []string
+	seen := make(map[string]bool)
+	for _, word := range words {
+		if word == "" {
+			return nil, fmt.Errorf("cannot search for an empty word")
+		}
+		if !seen[word] {
+			seen[word] = true
+			unique = append(unique, word)
+		}
+	}
+
+	return &WordSearch{words: unique, automaton: newAutomaton(unique)}, nil
+}
+
+// Words returns the distinct words searched for, in the order given
+func (s *WordSearch) Words() []string {
+	return s.words
+}
+
+// Find returns every match in the grid, ordered by start cell, then direction,
+// then word. Like findXMAS, a word that reads the same both ways, including any
+// single letter, matches once in each direction.
+func (s *WordSearch) Find(grid [][]rune) []Match {
+	var matches []Match
+	for _, line := range gridLines(grid) {
+		matches = s.scan(line, matches)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Row != b.Row {
+			return a.Row < b.Row
+		}
+		if a.Col != b.Col {
+			return a.Col < b.Col
+		}
+		if a.Direction != b.Direction {
+			return a.Direction < b.Direction
+		}
+		return a.Word < b.Word
+	})
+	return matches
+}
+
+// Count returns how many times each word occurs
+func (s *WordSearch) Count(grid [][]rune) map[string]int {
+	counts := make(map[string]int, len(s.words))
+	for _, word := range s.words {
+		counts[word] = 0
+	}
+	for _, line := range gridLines(grid) {
+		s.automaton.search(line.letters, func(word, _ int) {
+			counts[s.words[word]]++
+		})
+		s.automaton.search(reversed(line.letters), func(word, _ int) {
+			counts[s.words[word]]++
+		})
+	}
+	return counts
+}
+
+// scan appends the matches along line, read forwards and backwards
+func (s *WordSearch) scan(line gridLine, matches []Match) []Match {
+	n := len(line.letters)
+	s.automaton.search(line.letters, func(word, end int) {
+		start := end - len(s.automaton.words[word]) + 1
+		cell := line.cells[start]
+		matches = append(matches, Match{Word: s.words[word], Row: cell[0], Col: cell[1], Direction: line.direction})
+	})
+	s.automaton.search(reversed(line.letters), func(word, end int) {
+		// Position end in the reversed line is n-1-end in the line itself
+		start := n - 1 - (end - len(s.automaton.words[word]) + 1)
+		cell := line.cells[start]
+		matches = append(matches, Match{Word: s.words[word], Row: cell[0], Col: cell[1], Direction: line.direction.Reverse()})
+	})
+	return matches
+}
+
+// gridLine is the letters along one row, column or diagonal and their cells,
+// in the order of a forward direction
+type gridLine struct {
+	direction Direction
+	letters   []rune
+	cells     [][2]int
+}
+
+// gridLines returns every row, column and diagonal of a rectangular grid
+func gridLines(grid [][]rune) []gridLine {
+	if len(grid) == 0 || len(grid[0]) == 0 {
+		return nil
+	}
+	rows, cols := len(grid), len(grid[0])
+
+	var lines []gridLine
+	walk := func(direction Direction, row, col int) {
+		dr, dc := direction.Delta()
+		line := gridLine{direction: direction}
+		for ; row >= 0 && row < rows && col >= 0 && col < cols; row, col = row+dr, col+dc {
+			line.letters = append(line.letters, grid[row][col])
+			line.cells = append(line.cells, [2]int{row, col})
+		}
+		lines = append(lines, line)
+	}
+
+	for row := 0; row < rows; row++ {
+		walk(Right, row, 0)
+	}
+	for col := 0; col < cols; col++ {
+		walk(Down, 0, col)
+	}
+	// Diagonals start on the top row or down the left (down-right) or right
+	// (down-left) edge
+	for col := 0; col < cols; col++ {
+		walk(DownRight, 0, col)
+		walk(DownLeft, 0, col)
+	}
+	for row := 1; row < rows; row++ {
+		walk(DownRight, row, 0)
+		walk(DownLeft, row, cols-1)
+	}
+	return lines
+}
+
+func reversed(letters []rune) []rune {
+	result := make([]rune, len(letters))
+	for i, letter := range letters {
+		result[len(letters)-1-i] = letter
+	}
+	return result
+}
+
+// automaton is an Aho-Corasick automaton over runes. Each node is a prefix of
+// one or more words; fail points to the longest proper suffix that is also a
+// prefix, and output to the nearest node along the fail chain that ends a word.
+type automaton struct {
+	words  [][]rune
+	next   []map[rune]int
+	fail   []int
+	word   []int // index of the word ending at the node, or -1
+	output []int
+}
+
+func newAutomaton(words []string) *automaton {
+	a := &automaton{}
+	a.addNode()
+
+	for i, word := range words {
+		letters := []rune(word)
+		a.words = append(a.words, letters)
+
+		node := 0
+		for _, letter := range letters {
+			child, ok := a.next[node][letter]
+			if !ok {
+				child = a.addNode()
+				a.next[node][letter] = child
+			}
+			node = child
+		}
+		a.word[node] = i
+	}
+
+	// Breadth-first, so every fail link points to a node already finished
+	queue := []int{}
+	for _, child := range a.next[0] {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		for letter, child := range a.next[node] {
+			a.fail[child] = a.step(a.fail[node], letter)
+			if a.word[a.fail[child]] >= 0 {
+				a.output[child] = a.fail[child]
+			} else {
+				a.output[child] = a.output[a.fail[child]]
+			}
+			queue = append(queue, child)
+		}
+	}
+
+	return a
+}
+
+func (a *automaton) addNode() int {
+	a.next = append(a.next, make(map[rune]int))
+	a.fail = append(a.fail, 0)
+	a.word = append(a.word, -1)
+	a.output = append(a.output, -1)
+	return len(a.next) - 1
+}
+
+// step follows letter from node, falling back along fail links as needed
+func (a *automaton) step(node int, letter rune) int {
+	for {
+		if child, ok := a.next[node][letter]; ok {
+			return child
+		}
+		if node == 0 {
+			return 0
+		}
+		node = a.fail[node]
+	}
+}
+
+// search calls found with the word index and end position of every occurrence
+// of a word in text
+func (a *automaton) search(text []rune, found func(word, end int)) {
+	node := 0
+	for i, letter := range text {
+		node = a.step(node, letter)
+		for match := node; match > 0; match = a.output[match] {
+			if a.word[match] >= 0 {
+				found(a.word[match], i)
+			}
+		}
+	}
+}
+
+// WordCount is how often one word occurs
+type WordCount struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+// SearchReport lists how often each word occurs in a grid and where
+type SearchReport struct {
+	Counts  []WordCount `json:"counts"`
+	Matches []Match     `json:"matches"`
+}
+
+// SearchFile searches the grid in filename for words
+func SearchFile(filename string, words []string) (*SearchReport, error) {
+	search, err := NewWordSearch(words)
+	if err != nil {
+		return nil, err
+	}
+
+	grid, err := parseGrid(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SearchReport{Matches: search.Find(grid)}
+	counts := make(map[string]int)
+	for _, match := range report.Matches {
+		counts[match.Word]++
+	}
+	for _, word := range search.Words() {
+		report.Counts = append(report.Counts, WordCount{Word: word, Count: counts[word]})
+	}
+	if report.Matches == nil {
+		report.Matches = []Match{}
+	}
+	return report, nil
+}
+
+// WriteCounts writes each word with its number of matches, and the total
+func (r *SearchReport) WriteCounts(w io.Writer) error {
+	var out strings.Builder
+
+	width := len("Total")
+	for _, count := range r.Counts {
+		width = max(width, len(count.Word))
+	}
+
+	fmt.Fprintf(&out, "%-*s %8s\n", width, "Word", "Matches")
+	for _, count := range r.Counts {
+		fmt.Fprintf(&out, "%-*s %8d\n", width, count.Word, count.Count)
+	}
+	fmt.Fprintf(&out, "%-*s %8d\n", width, "Total", len(r.Matches))
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// WriteMatches writes one line per match with its start cell and direction
+func (r *SearchReport) WriteMatches(w io.Writer) error {
+	var out strings.Builder
+
+	for _, match := range r.Matches {
+		fmt.Fprintf(&out, "%s at (%d,%d) reading %s\n", match.Word, match.Row, match.Col, match.Direction)
+	}
+	fmt.Fprintf(&out, "%d matches\n", len(r.Matches))
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// ReadWordList reads one word per line from filename, skipping blank lines and
+// lines starting with #
+func ReadWordList(filename string) ([]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return readWordList(file)
+}
+
+func readWordList(r io.Reader) ([]string, error) {
+	var words []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		words = append(words, word)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return words, nil
+}
@@ -0,0 +1,190 @@
+package day04
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"advent-of-code-2024/internal/gen"
+)
+
+// bruteForceMatches checks every word in every direction from every cell
+func bruteForceMatches(grid [][]rune, words []string) []Match {
+	var matches []Match
+	for row := range grid {
+		for col := range grid[row] {
+			for _, direction := range Directions {
+				deltaRow, deltaCol := direction.Delta()
+				for _, word := range words {
+					if checkStringInDirection(grid, row, col, deltaRow, deltaCol, word) {
+						matches = append(matches, Match{Word: word, Row: row, Col: col, Direction: direction})
+					}
+				}
+			}
+		}
+	}
+	return matches
+}
+
+func TestWordSearchFindXMAS(t *testing.T) {
+	grid, err := parseGrid("example-input.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	search, err := NewWordSearch([]string{"XMAS"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matches := search.Find(grid)
+	if len(matches) != 18 {
+		t.Errorf("Find() returned %d matches, expected 18", len(matches))
+	}
+	if first := matches[0]; first != (Match{Word: "XMAS", Row: 0, Col: 4, Direction: DownRight}) {
+		t.Errorf("first match = %+v, expected XMAS at (0,4) reading down-right", first)
+	}
+	if counts := search.Count(grid); counts["XMAS"] != 18 {
+		t.Errorf("Count() = %v, expected 18 XMAS", counts)
+	}
+}
+
+func TestWordSearchMatchesBruteForce(t *testing.T) {
+	// Words that overlap, nest and read the same backwards stress the fail and output links
+	words := []string{"XMAS", "SAMX", "MAS", "AS", "A", "MAM", "XMASAMX", "SS"}
+
+	inputs := []string{"XMASAMX\nMASAMXM\nSSASSAA", "A", "AM\nMA"}
+	for seed := int64(1); seed <= 3; seed++ {
+		input, err := gen.String(4, 12, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, input)
+	}
+
+	search, err := NewWordSearch(words)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, input := range inputs {
+		grid, err := parseGridReader(strings.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := bruteForceMatches(grid, words)
+		matches := search.Find(grid)
+		if len(matches) != len(expected) {
+			t.Fatalf("Find() returned %d matches, expected %d for %q", len(matches), len(expected), input)
+		}
+
+		// Both are ordered by cell and direction; only the word order may differ
+		seen := make(map[Match]int)
+		for _, match := range expected {
+			seen[match]++
+		}
+		for _, match := range matches {
+			seen[match]--
+		}
+		for match, n := range seen {
+			if n != 0 {
+				t.Errorf("match %+v found %d times too few by Find() in %q", match, n, input)
+			}
+		}
+
+		counts := search.Count(grid)
+		for _, word := range words {
+			want := 0
+			for _, match := range expected {
+				if match.Word == word {
+					want++
+				}
+			}
+			if counts[word] != want {
+				t.Errorf("Count()[%s] = %d, expected %d in %q", word, counts[word], want, input)
+			}
+		}
+	}
+}
+
+func TestNewWordSearchErrors(t *testing.T) {
+	if _, err := NewWordSearch(nil); err == nil {
+		t.Error("NewWordSearch(nil) should fail")
+	}
+	if _, err := NewWordSearch([]string{"XMAS", ""}); err == nil {
+		t.Error("NewWordSearch() with an empty word should fail")
+	}
+
+	search, err := NewWordSearch([]string{"XMAS", "MAS", "XMAS"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if words := search.Words(); len(words) != 2 || words[0] != "XMAS" || words[1] != "MAS" {
+		t.Errorf("Words() = %v, expected [XMAS MAS]", words)
+	}
+}
+
+func TestDirectionJSON(t *testing.T) {
+	data, err := json.Marshal(Match{Word: "XMAS", Row: 1, Col: 2, Direction: UpLeft})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"direction":"up-left"`) {
+		t.Errorf("Match JSON %s should name the direction", data)
+	}
+
+	var match Match
+	if err := json.Unmarshal(data, &match); err != nil || match.Direction != UpLeft {
+		t.Errorf("Unmarshal() = %+v, %v, expected up-left", match, err)
+	}
+
+	for _, direction := range Directions {
+		dr, dc := direction.Delta()
+		rr, rc := direction.Reverse().Delta()
+		if rr != -dr || rc != -dc {
+			t.Errorf("%s reversed is %s", direction, direction.Reverse())
+		}
+	}
+}
+
+func TestReadWordList(t *testing.T) {
+	words, err := readWordList(strings.NewReader("# search terms\nXMAS\n\n  SAMX  \n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(words) != 2 || words[0] != "XMAS" || words[1] != "SAMX" {
+		t.Errorf("readWordList() = %q, expected [XMAS SAMX]", words)
+	}
+}
+
+func TestSearchFile(t *testing.T) {
+	report, err := SearchFile("example-input.txt", []string{"XMAS", "MAS", "ZZZ"})
+	if err != nil {
+		t.Fatalf("SearchFile failed: %v", err)
+	}
+
+	expected := []WordCount{{"XMAS", 18}, {"MAS", 38}, {"ZZZ", 0}}
+	for i, count := range report.Counts {
+		if count != expected[i] {
+			t.Errorf("Counts[%d] = %+v, expected %+v", i, count, expected[i])
+		}
+	}
+
+	var out bytes.Buffer
+	if err := report.WriteCounts(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "XMAS        18\n") || !strings.Contains(out.String(), "Total       56\n") {
+		t.Errorf("WriteCounts() output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := report.WriteMatches(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "\nXMAS at (0,4) reading down-right\n") || !strings.HasSuffix(out.String(), "56 matches\n") {
+		t.Errorf("WriteMatches() output:\n%s", out.String())
+	}
+}
@@ -0,0 +1,233 @@
+package day04
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Wildcard marks a stencil cell that matches any letter
+const Wildcard = '.'
+
+// xmasStencil is the part 2 shape: two MAS crossing on their A. Its rotations
+// cover every way of writing each MAS forwards or backwards.
+var xmasStencil = mustParseStencil("M.S\n.A.\nM.S")
+
+// Stencil is a small rectangle of letters to find in the grid, in any rotation
+// or reflection. Wildcard cells match anything, so a stencil can describe shapes
+// that are not rectangles.
+type Stencil struct {
+	cells [][]rune
+}
+
+// ParseStencil reads a stencil from text, one row per line. Short rows are
+// padded with wildcards, and at least one cell must be a letter.
+func ParseStencil(text string) (Stencil, error) {
+	var cells [][]rune
+	width := 0
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		row := []rune(strings.TrimRight(line, "\r"))
+		cells = append(cells, row)
+		width = max(width, len(row))
+	}
+
+	letters := 0
+	for i, row := range cells {
+		for _, cell := range row {
+			if cell != Wildcard {
+				letters++
+			}
+		}
+		for len(row) < width {
+			row = append(row, Wildcard)
+		}
+		cells[i] = row
+	}
+	if letters == 0 {
+		return Stencil{}, fmt.Errorf("stencil %q has no letters", text)
+	}
+
+	return Stencil{cells: cells}, nil
+}
+
+func mustParseStencil(text string) Stencil {
+	stencil, err := ParseStencil(text)
+	if err != nil {
+		panic(err)
+	}
+	return stencil
+}
+
+// Size returns the stencil's height and width
+func (s Stencil) Size() (int, int) {
+	if len(s.cells) == 0 {
+		return 0, 0
+	}
+	return len(s.cells), len(s.cells[0])
+}
+
+// String returns the stencil in the text form ParseStencil reads
+func (s Stencil) String() string {
+	rows := make([]string, len(s.cells))
+	for i, row := range s.cells {
+		rows[i] = string(row)
+	}
+	return strings.Join(rows, "\n")
+}
+
+// rotate turns the stencil a quarter turn clockwise
+func (s Stencil) rotate() Stencil {
+	height, width := s.Size()
+	cells := make([][]rune, width)
+	for row := range cells {
+		cells[row] = make([]rune, height)
+		for col := range cells[row] {
+			cells[row][col] = s.cells[height-1-col][row]
+		}
+	}
+	return Stencil{cells: cells}
+}
+
+// reflect mirrors the stencil left to right
+func (s Stencil) reflect() Stencil {
+	cells := make([][]rune, len(s.cells))
+	for row, letters := range s.cells {
+		cells[row] = reversed(letters)
+	}
+	return Stencil{cells: cells}
+}
+
+// shape returns the stencil's letters as row, column and letter, with the
+// offsets shifted so the topmost and leftmost letters are at 0. Stencils with
+// the same shape match the same cells, whatever wildcards pad them.
+func (s Stencil) shape() string {
+	top, left := len(s.cells), len(s.cells)
+	for row, letters := range s.cells {
+		for col, letter := range letters {
+			if letter != Wildcard {
+				top, left = min(top, row), min(left, col)
+			}
+		}
+		left = min(left, len(letters))
+	}
+
+	var b strings.Builder
+	for row, letters := range s.cells {
+		for col, letter := range letters {
+			if letter != Wildcard {
+				fmt.Fprintf(&b, "%d,%d,%c;", row-top, col-left, letter)
+			}
+		}
+	}
+	return b.String()
+}
+
+// Orientations returns the stencil's four rotations and their reflections,
+// starting with the stencil itself, with duplicates from any symmetry removed.
+// Two orientations are duplicates when their letters have the same shape, even
+// if the wildcards around them differ.
+func (s Stencil) Orientations() []Stencil {
+	var orientations []Stencil
+	seen := make(map[string]bool)
+
+	for _, start := range []Stencil{s, s.reflect()} {
+		current := start
+		for range 4 {
+			if key := current.shape(); !seen[key] {
+				seen[key] = true
+				orientations = append(orientations, current)
+			}
+			current = current.rotate()
+		}
+	}
+	return orientations
+}
+
+// matchesAt reports whether every letter of the stencil is in the grid with its
+// top-left corner at row, col. On a wrapping grid each letter must land on a
+// different cell.
+func (s Stencil) matchesAt(grid [][]rune, row, col int, topology Topology) bool {
+	var cells [][2]int
+	for dr, letters := range s.cells {
+		for dc, letter := range letters {
+			if letter == Wildcard {
+				continue
+			}
+			r, c, ok := topology.cell(grid, row+dr, col+dc)
+			if !ok || grid[r][c] != letter {
+				return false
+			}
+			if topology.wraps() {
+				for _, seen := range cells {
+					if seen == [2]int{r, c} {
+						return false
+					}
+				}
+				cells = append(cells, [2]int{r, c})
+			}
+		}
+	}
+	return true
+}
+
+// StencilMatch is a place where the stencil fits the grid: Row and Col are the
+// top-left corner, and Orientation indexes the stencil's Orientations
+type StencilMatch struct {
+	Row         int `json:"row"`
+	Col         int `json:"col"`
+	Orientation int `json:"orientation"`
+}
+
+// FindStencil returns every placement of any orientation of s in the grid,
+// ordered by corner and then orientation
+func FindStencil(grid [][]rune, s Stencil) []StencilMatch {
+	return FindStencilOn(grid, s, Topology{})
+}
+
+// FindStencilOn is FindStencil on a grid whose edges behave as topology says.
+// Unless an axis wraps, the whole stencil must fit within the grid's rows and
+// its widest row; on a ragged grid only its letters need cells under them. When
+// columns wrap, each corner is a cell of its own row, so no placement is found
+// twice.
+func FindStencilOn(grid [][]rune, s Stencil, topology Topology) []StencilMatch {
+	if len(grid) == 0 {
+		return nil
+	}
+
+	return findStencilInRows(grid, s.Orientations(), topology, 0, len(grid), nil)
+}
+
+// gridWidth is the width FindStencilOn places stencils across: the first
+// row's, or the widest row's on a ragged grid
+func gridWidth(grid [][]rune, topology Topology) int {
+	width := len(grid[0])
+	if topology.Ragged {
+		for _, row := range grid {
+			width = max(width, len(row))
+		}
+	}
+	return width
+}
+
+// findStencilInRows appends to matches the placements of orientations whose
+// top-left corner is in rows [from, to)
+func findStencilInRows(grid [][]rune, orientations []Stencil, topology Topology, from, to int, matches []StencilMatch) []StencilMatch {
+	width := gridWidth(grid, topology)
+	for row := from; row < to; row++ {
+		corners := width
+		if topology.WrapCols {
+			corners = len(grid[row])
+		}
+		for col := 0; col < corners; col++ {
+			for i, orientation := range orientations {
+				height, stencilWidth := orientation.Size()
+				if !topology.WrapRows && row+height > len(grid) || !topology.WrapCols && col+stencilWidth > width {
+					continue
+				}
+				if orientation.matchesAt(grid, row, col, topology) {
+					matches = append(matches, StencilMatch{Row: row, Col: col, Orientation: i})
+				}
+			}
+		}
+	}
+	return matches
+}
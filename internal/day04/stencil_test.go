@@ -0,0 +1,157 @@
+package day04
+
+import (
+	"strings"
+	"testing"
+
+	"advent-of-code-2024/internal/gen"
+)
+
+func TestParseStencil(t *testing.T) {
+	stencil, err := ParseStencil("M.S\r\n.A\nM.S\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := stencil.String(); got != "M.S\n.A.\nM.S" {
+		t.Errorf("String() = %q, expected short rows padded with wildcards", got)
+	}
+	if height, width := stencil.Size(); height != 3 || width != 3 {
+		t.Errorf("Size() = %d, %d, expected 3, 3", height, width)
+	}
+
+	for _, text := range []string{"", "\n", "...\n.."} {
+		if _, err := ParseStencil(text); err == nil {
+			t.Errorf("ParseStencil(%q) expected error", text)
+		}
+	}
+}
+
+func TestStencilOrientations(t *testing.T) {
+	tests := []struct {
+		stencil  string
+		expected int
+	}{
+		{"A", 1},
+		{"AA\nAA", 1},
+		{"XMAS", 4},
+		{"M.S\n.A.\nM.S", 4},
+		{".M.\nMAS\n.S.", 4},
+		{"AB\nCD", 8},
+		{"XM\nA.", 8},
+		{"M.S\n.A.\nM.S\n...", 4},
+	}
+
+	for _, test := range tests {
+		orientations := mustParseStencil(test.stencil).Orientations()
+		if len(orientations) != test.expected {
+			t.Errorf("Orientations() of %q has %d stencils, expected %d", test.stencil, len(orientations), test.expected)
+		}
+		if orientations[0].String() != test.stencil {
+			t.Errorf("Orientations() of %q starts with %q, expected the stencil itself", test.stencil, orientations[0])
+		}
+	}
+}
+
+func TestStencilRotate(t *testing.T) {
+	rotated := mustParseStencil("XM\nA.\nS.").rotate()
+	if got := rotated.String(); got != "SAX\n..M" {
+		t.Errorf("rotate() = %q, expected %q", got, "SAX\n..M")
+	}
+	if got := rotated.rotate().rotate().rotate().String(); got != "XM\nA.\nS." {
+		t.Errorf("four rotations = %q, expected the original stencil", got)
+	}
+}
+
+func TestFindStencil(t *testing.T) {
+	grid := [][]rune{
+		[]rune("XMAS"),
+		[]rune("SAMX"),
+		[]rune("AXMA"),
+	}
+
+	// XMAS reads right from (0,0) and left from (1,3), while its upright
+	// orientations are too tall for the grid
+	matches := FindStencil(grid, mustParseStencil("XMAS"))
+	expected := []StencilMatch{{Row: 0, Col: 0, Orientation: 0}, {Row: 1, Col: 0, Orientation: 2}}
+	if len(matches) != len(expected) {
+		t.Fatalf("FindStencil() = %+v, expected %+v", matches, expected)
+	}
+	for i := range matches {
+		if matches[i] != expected[i] {
+			t.Errorf("FindStencil()[%d] = %+v, expected %+v", i, matches[i], expected[i])
+		}
+	}
+
+	if matches := FindStencil(nil, xmasStencil); len(matches) != 0 {
+		t.Errorf("FindStencil() on an empty grid = %+v, expected none", matches)
+	}
+}
+
+func TestFindStencilPlus(t *testing.T) {
+	grid := [][]rune{
+		[]rune(".S.S."),
+		[]rune("MASAM"),
+		[]rune(".M.M."),
+	}
+
+	matches := FindStencil(grid, mustParseStencil(".M.\nMAS\n.S."))
+	if len(matches) != 2 {
+		t.Fatalf("FindStencil() = %+v, expected a plus centred on each A", matches)
+	}
+	if matches[0].Row != 0 || matches[0].Col != 0 || matches[1].Row != 0 || matches[1].Col != 2 {
+		t.Errorf("FindStencil() = %+v, expected corners (0,0) and (0,2)", matches)
+	}
+}
+
+func TestFindStencilPadded(t *testing.T) {
+	grid := [][]rune{
+		[]rune("..."),
+		[]rune("M.S"),
+		[]rune(".A."),
+		[]rune("M.S"),
+		[]rune("..."),
+	}
+
+	// Turned half way and mirrored, the padded stencil is the same X-MAS with
+	// the padding row on top, which must not find this X-MAS a second time
+	matches := FindStencil(grid, mustParseStencil("M.S\n.A.\nM.S\n..."))
+	expected := StencilMatch{Row: 1, Col: 0, Orientation: 0}
+	if len(matches) != 1 || matches[0] != expected {
+		t.Errorf("FindStencil() = %+v, expected only %+v", matches, expected)
+	}
+}
+
+// diagonalXMAS is the X-MAS check written out by hand: MAS or SAM along both
+// diagonals through an A
+func diagonalXMAS(grid [][]rune, row, col int) bool {
+	diagonal := func(startCol, deltaCol int) bool {
+		return checkStringInDirection(grid, row-1, startCol, 1, deltaCol, "MAS") ||
+			checkStringInDirection(grid, row-1, startCol, 1, deltaCol, "SAM")
+	}
+	return diagonal(col-1, 1) && diagonal(col+1, -1)
+}
+
+func TestFindStencilMatchesDiagonals(t *testing.T) {
+	for seed := int64(1); seed <= 5; seed++ {
+		input, err := gen.String(4, 20, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		grid, err := parseGridReader(strings.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := 0
+		for row := range grid {
+			for col := range grid[row] {
+				if diagonalXMAS(grid, row, col) {
+					expected++
+				}
+			}
+		}
+		if got := len(FindStencil(grid, xmasStencil)); got != expected {
+			t.Errorf("seed %d: FindStencil() found %d X-MAS, expected %d", seed, got, expected)
+		}
+	}
+}
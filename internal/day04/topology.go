@@ -0,0 +1,136 @@
+package day04
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Topology sets how the edges of the grid behave. The zero value is the
+// puzzle's: a rectangle whose edges are walls.
+type Topology struct {
+	WrapRows bool // stepping off the top or bottom comes back in at the other edge
+	WrapCols bool // stepping off the left or right comes back in at the other edge
+	Ragged   bool // rows may differ in length, and each row ends at its own last letter
+}
+
+// ParseWrap reads a wrap setting: "none", "rows", "cols" or "both"
+func (t *Topology) ParseWrap(wrap string) error {
+	switch wrap {
+	case "none", "":
+		t.WrapRows, t.WrapCols = false, false
+	case "rows":
+		t.WrapRows, t.WrapCols = true, false
+	case "cols":
+		t.WrapRows, t.WrapCols = false, true
+	case "both":
+		t.WrapRows, t.WrapCols = true, true
+	default:
+		return fmt.Errorf("unknown wrap %q, expected none, rows, cols or both", wrap)
+	}
+	return nil
+}
+
+func (t Topology) String() string {
+	var parts []string
+	switch {
+	case t.WrapRows && t.WrapCols:
+		parts = append(parts, "torus")
+	case t.WrapRows:
+		parts = append(parts, "rows wrap")
+	case t.WrapCols:
+		parts = append(parts, "columns wrap")
+	}
+	if t.Ragged {
+		parts = append(parts, "ragged")
+	}
+	if len(parts) == 0 {
+		return "rectangle"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// cell maps row, col onto the grid, wrapping it around any axis that wraps. It
+// returns false if the position is off the grid.
+func (t Topology) cell(grid [][]rune, row, col int) (int, int, bool) {
+	if len(grid) == 0 {
+		return 0, 0, false
+	}
+
+	if t.WrapRows {
+		row = wrap(row, len(grid))
+	}
+	if row < 0 || row >= len(grid) {
+		return 0, 0, false
+	}
+
+	// A rectangle takes its width from the first row, so a grid that is ragged
+	// by mistake still reads the same columns on every row
+	width := len(grid[0])
+	if t.Ragged {
+		width = len(grid[row])
+	}
+	if t.WrapCols && width > 0 {
+		col = wrap(col, width)
+	}
+	if col < 0 || col >= width || col >= len(grid[row]) {
+		return 0, 0, false
+	}
+	return row, col, true
+}
+
+func wrap(i, n int) int {
+	return ((i % n) + n) % n
+}
+
+// readsAt reports whether target can be read from row, col stepping by deltaRow,
+// deltaCol. Each letter must come from a different cell, so on a wrapping grid
+// a word cannot run into its own start.
+func (t Topology) readsAt(grid [][]rune, row, col, deltaRow, deltaCol int, target string) bool {
+	var cells [][2]int
+	for i, char := range target {
+		r, c, ok := t.cell(grid, row+i*deltaRow, col+i*deltaCol)
+		if !ok || grid[r][c] != char {
+			return false
+		}
+		if t.wraps() {
+			for _, seen := range cells {
+				if seen == [2]int{r, c} {
+					return false
+				}
+			}
+			cells = append(cells, [2]int{r, c})
+		}
+	}
+	return true
+}
+
+func (t Topology) wraps() bool {
+	return t.WrapRows || t.WrapCols
+}
+
+// parseGridWith reads the grid in filename, allowing rows of different lengths
+// if the topology is ragged
+func parseGridWith(filename string, topology Topology) ([][]rune, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return readGrid(file, topology.Ragged)
+}
+
+// SolveWithTopology solves part 1 or part 2 on a grid whose edges behave as
+// topology says
+func SolveWithTopology(filename string, part int, topology Topology) (int, error) {
+	grid, err := parseGridWith(filename, topology)
+	if err != nil {
+		return 0, err
+	}
+
+	if part == 1 {
+		return findXMASOn(grid, topology), nil
+	}
+	return findXMASPatternOn(grid, topology), nil
+}
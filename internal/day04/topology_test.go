@@ -0,0 +1,158 @@
+package day04
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTopologyCell(t *testing.T) {
+	grid := [][]rune{
+		[]rune("ABC"),
+		[]rune("D"),
+		[]rune("EFGH"),
+	}
+
+	tests := []struct {
+		name                     string
+		topology                 Topology
+		row, col                 int
+		expectedOK               bool
+		expectedRow, expectedCol int
+	}{
+		{"inside", Topology{}, 2, 2, true, 2, 2},
+		{"above a wall", Topology{}, -1, 0, false, 0, 0},
+		{"short row", Topology{}, 1, 1, false, 0, 0},
+		{"rectangle width", Topology{}, 2, 3, false, 0, 0},
+		{"ragged row length", Topology{Ragged: true}, 2, 3, true, 2, 3},
+		{"rows wrap", Topology{WrapRows: true}, -1, 1, true, 2, 1},
+		{"columns wrap", Topology{WrapCols: true}, 0, -1, true, 0, 2},
+		{"columns wrap within a ragged row", Topology{WrapCols: true, Ragged: true}, 2, 5, true, 2, 1},
+		{"columns wrap into a short row", Topology{WrapCols: true, Ragged: true}, 1, 3, true, 1, 0},
+		{"both wrap", Topology{WrapRows: true, WrapCols: true}, 3, 4, true, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row, col, ok := tt.topology.cell(grid, tt.row, tt.col)
+			if ok != tt.expectedOK || ok && (row != tt.expectedRow || col != tt.expectedCol) {
+				t.Errorf("cell(%d, %d) = %d, %d, %v, expected %d, %d, %v",
+					tt.row, tt.col, row, col, ok, tt.expectedRow, tt.expectedCol, tt.expectedOK)
+			}
+		})
+	}
+}
+
+func TestTopologyWordCannotReuseCells(t *testing.T) {
+	grid := [][]rune{[]rune("AB")}
+	torus := Topology{WrapRows: true, WrapCols: true}
+
+	if !torus.readsAt(grid, 0, 1, 0, 1, "BA") {
+		t.Error("readsAt() = false for BA across the edge, expected true")
+	}
+	if torus.readsAt(grid, 0, 0, 0, 1, "ABAB") {
+		t.Error("readsAt() = true for ABAB running into its own start, expected false")
+	}
+}
+
+func TestFindXMASOn(t *testing.T) {
+	tests := []struct {
+		name     string
+		grid     string
+		topology Topology
+		expected int
+	}{
+		{"wall", "MASX", Topology{}, 0},
+		{"columns wrap", "MASX", Topology{WrapCols: true}, 1},
+		{"rows do not wrap", "M\nA\nS\nX", Topology{WrapCols: true}, 0},
+		{"rows wrap", "M\nA\nS\nX", Topology{WrapRows: true}, 1},
+		{"ragged", "XMAS\nM\nAXMAS\nS", Topology{Ragged: true}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grid, err := readGrid(strings.NewReader(tt.grid), tt.topology.Ragged)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result := findXMASOn(grid, tt.topology); result != tt.expected {
+				t.Errorf("findXMASOn() = %d, expected %d", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFindXMASPatternOnTorus(t *testing.T) {
+	// The only A is in the corner, and its X-MAS wraps across both edges
+	grid := [][]rune{
+		[]rune("A.."),
+		[]rune(".SM"),
+		[]rune(".SM"),
+	}
+
+	if result := findXMASPatternOn(grid, Topology{}); result != 0 {
+		t.Errorf("findXMASPatternOn() on a rectangle = %d, expected 0", result)
+	}
+	if result := findXMASPatternOn(grid, Topology{WrapRows: true, WrapCols: true}); result != 1 {
+		t.Errorf("findXMASPatternOn() on a torus = %d, expected 1", result)
+	}
+}
+
+func TestFindStencilOnRaggedWrapCountsOnce(t *testing.T) {
+	// The short row must not be matched again from corners past its end
+	grid := [][]rune{
+		[]rune("AB"),
+		[]rune("ABABAB"),
+	}
+
+	matches := FindStencilOn(grid, mustParseStencil("A"), Topology{WrapCols: true, Ragged: true})
+	if len(matches) != 4 {
+		t.Errorf("FindStencilOn() = %+v, expected each A once", matches)
+	}
+}
+
+func TestSolveWithTopology(t *testing.T) {
+	tests := []struct {
+		part     int
+		topology Topology
+		expected int
+	}{
+		{1, Topology{}, 18},
+		{2, Topology{}, 9},
+		{1, Topology{Ragged: true}, 18},
+		{2, Topology{Ragged: true}, 9},
+	}
+
+	for _, tt := range tests {
+		result, err := SolveWithTopology("example-input.txt", tt.part, tt.topology)
+		if err != nil {
+			t.Fatalf("SolveWithTopology() error = %v", err)
+		}
+		if result != tt.expected {
+			t.Errorf("SolveWithTopology(part %d, %s) = %d, expected %d", tt.part, tt.topology, result, tt.expected)
+		}
+	}
+
+	// Wrapping only adds matches that cross an edge
+	for part := 1; part <= 2; part++ {
+		result, err := SolveWithTopology("example-input.txt", part, Topology{WrapRows: true, WrapCols: true})
+		if err != nil {
+			t.Fatalf("SolveWithTopology() error = %v", err)
+		}
+		if minimum := []int{18, 9}[part-1]; result < minimum {
+			t.Errorf("SolveWithTopology(part %d, torus) = %d, expected at least %d", part, result, minimum)
+		}
+	}
+}
+
+func TestParseWrap(t *testing.T) {
+	var topology Topology
+	if err := topology.ParseWrap("both"); err != nil || !topology.WrapRows || !topology.WrapCols {
+		t.Errorf("ParseWrap(both) = %+v, %v", topology, err)
+	}
+	if err := topology.ParseWrap("none"); err != nil || topology.WrapRows || topology.WrapCols {
+		t.Errorf("ParseWrap(none) = %+v, %v", topology, err)
+	}
+	if err := topology.ParseWrap("sideways"); err == nil {
+		t.Error("ParseWrap(sideways) expected error")
+	}
+}
@@ -0,0 +1,380 @@
+package day05
+
+import (
+	"fmt"
+	"io"
+	"math/bits"
+	"sort"
+	"strings"
+)
+
+// bitset is a set of page indices
+type bitset []uint64
+
+func newBitset(n int) bitset {
+	return make(bitset, (n+63)/64)
+}
+
+func (b bitset) set(i int) {
+	b[i/64] |= 1 << (i % 64)
+}
+
+func (b bitset) has(i int) bool {
+	return b[i/64]&(1<<(i%64)) != 0
+}
+
+func (b bitset) union(other bitset) {
+	for i := range b {
+		b[i] |= other[i]
+	}
+}
+
+func (b bitset) count() int {
+	n := 0
+	for _, word := range b {
+		n += bits.OnesCount64(word)
+	}
+	return n
+}
+
+// Closure is the transitive closure of a set of rules: which pages must come
+// before which once every chain of rules is followed
+type Closure struct {
+	pages      []int       // distinct pages named in the rules, ascending
+	index      map[int]int // page -> position in pages
+	successors [][]int     // distinct direct successors of each page index
+	reach      []bitset    // page indices each page must come before
+	components [][]int     // strongly connected components, sinks first
+}
+
+// NewClosure follows every chain of rules. Pages on a cycle of rules must come
+// before themselves, and before every other page on the cycle.
+func NewClosure(rules []OrderingRule) *Closure {
+	c := &Closure{index: make(map[int]int)}
+	for _, rule := range rules {
+		for _, page := range []int{rule.Before, rule.After} {
+			if _, ok := c.index[page]; !ok {
+				c.index[page] = -1
+				c.pages = append(c.pages, page)
+			}
+		}
+	}
+	sort.Ints(c.pages)
+	for i, page := range c.pages {
+		c.index[page] = i
+	}
+
+	n := len(c.pages)
+	direct := make([]bitset, n)
+	for i := range direct {
+		direct[i] = newBitset(n)
+	}
+	c.successors = make([][]int, n)
+	for _, rule := range rules {
+		from, to := c.index[rule.Before], c.index[rule.After]
+		if !direct[from].has(to) {
+			direct[from].set(to)
+			c.successors[from] = append(c.successors[from], to)
+		}
+	}
+
+	c.components = stronglyConnected(c.successors)
+
+	// Components come sinks first, so each one's successors are finished
+	// before it: a page reaches its successors and everything they reach
+	c.reach = make([]bitset, n)
+	for _, component := range c.components {
+		reach := newBitset(n)
+		cyclic := len(component) > 1 || direct[component[0]].has(component[0])
+		for _, v := range component {
+			if cyclic {
+				reach.set(v)
+			}
+			for _, w := range c.successors[v] {
+				reach.set(w)
+				if c.reach[w] != nil {
+					reach.union(c.reach[w])
+				}
+			}
+		}
+		for _, v := range component {
+			c.reach[v] = reach
+		}
+	}
+	return c
+}
+
+// stronglyConnected returns the strongly connected components of the graph
+// using Tarjan's algorithm, which finds them in reverse topological order
+func stronglyConnected(successors [][]int) [][]int {
+	n := len(successors)
+	index := make([]int, n)
+	low := make([]int, n)
+	onStack := make([]bool, n)
+	for i := range index {
+		index[i] = -1
+	}
+
+	var stack []int
+	var components [][]int
+	next := 0
+
+	var visit func(v int)
+	visit = func(v int) {
+		index[v], low[v] = next, next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range successors[v] {
+			switch {
+			case index[w] < 0:
+				visit(w)
+				low[v] = min(low[v], low[w])
+			case onStack[w]:
+				low[v] = min(low[v], index[w])
+			}
+		}
+
+		if low[v] == index[v] {
+			var component []int
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				component = append(component, w)
+				if w == v {
+					break
+				}
+			}
+			sort.Ints(component)
+			components = append(components, component)
+		}
+	}
+
+	for v := range successors {
+		if index[v] < 0 {
+			visit(v)
+		}
+	}
+	return components
+}
+
+// Pages returns the distinct pages named in the rules, ascending
+func (c *Closure) Pages() []int {
+	return c.pages
+}
+
+// Implies reports whether the rules, followed through any chain, put before
+// ahead of after
+func (c *Closure) Implies(before, after int) bool {
+	from, ok := c.index[before]
+	if !ok {
+		return false
+	}
+	to, ok := c.index[after]
+	return ok && c.reach[from].has(to)
+}
+
+// Cycles returns one cycle of rules through each group of pages that must
+// come before themselves, starting from the group's smallest page
+func (c *Closure) Cycles() [][]int {
+	var cycles [][]int
+	for _, component := range c.components {
+		start := component[0]
+		if !c.reach[start].has(start) {
+			continue
+		}
+
+		inComponent := make(map[int]bool, len(component))
+		for _, v := range component {
+			inComponent[v] = true
+		}
+
+		// Breadth-first from start until an edge leads back to it, staying
+		// inside the component
+		parent := map[int]int{start: -1}
+		queue := []int{start}
+		last := -1
+		for len(queue) > 0 && last < 0 {
+			v := queue[0]
+			queue = queue[1:]
+			for _, w := range c.successors[v] {
+				if w == start {
+					last = v
+					break
+				}
+				if _, seen := parent[w]; !seen && inComponent[w] {
+					parent[w] = v
+					queue = append(queue, w)
+				}
+			}
+		}
+
+		var cycle []int
+		for v := last; v >= 0; v = parent[v] {
+			cycle = append(cycle, c.pages[v])
+		}
+		for i, j := 0, len(cycle)-1; i < j; i, j = i+1, j-1 {
+			cycle[i], cycle[j] = cycle[j], cycle[i]
+		}
+		cycles = append(cycles, cycle)
+	}
+
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+// RuleAnalysis describes whether a set of rules is self-consistent
+type RuleAnalysis struct {
+	Pages   int `json:"pages"`   // distinct pages named in the rules
+	Rules   int `json:"rules"`   // rules as given, duplicates included
+	Implied int `json:"implied"` // ordered pairs of pages the closure puts in order
+
+	// Cycles holds one cycle through each group of pages that the rules
+	// require to come before themselves
+	Cycles [][]int `json:"cycles"`
+
+	// Redundant rules follow from the others, or repeat an earlier rule. They
+	// are only looked for when the rules have no cycles, where a single pass
+	// over the closure finds them.
+	RedundantCount int            `json:"redundantCount"`
+	Redundant      []OrderingRule `json:"redundant"`
+
+	// Unordered pairs of pages have no chain of rules between them either way
+	UnorderedCount int      `json:"unorderedCount"`
+	Unordered      [][2]int `json:"unordered"`
+
+	// TotalOrder puts every page in the one order the rules allow, if there is
+	// exactly one
+	TotalOrder []int `json:"totalOrder,omitempty"`
+}
+
+// AnalyseRulesFile reads the input in filename and analyses its rules,
+// listing at most topK redundant rules and unordered pairs
+func AnalyseRulesFile(filename string, topK int) (*RuleAnalysis, error) {
+	input, err := readInput(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return AnalyseRules(input.Rules, topK), nil
+}
+
+// AnalyseRules computes the closure of rules and checks it for cycles,
+// redundant rules and pages left unordered, listing at most topK of the last two
+func AnalyseRules(rules []OrderingRule, topK int) *RuleAnalysis {
+	topK = max(topK, 0)
+	closure := NewClosure(rules)
+	n := len(closure.pages)
+
+	analysis := &RuleAnalysis{
+		Pages:     n,
+		Rules:     len(rules),
+		Cycles:    closure.Cycles(),
+		Redundant: []OrderingRule{},
+		Unordered: [][2]int{},
+	}
+	if analysis.Cycles == nil {
+		analysis.Cycles = [][]int{}
+	}
+	for _, reach := range closure.reach {
+		analysis.Implied += reach.count()
+	}
+
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if closure.reach[i].has(j) || closure.reach[j].has(i) {
+				continue
+			}
+			analysis.UnorderedCount++
+			if len(analysis.Unordered) < topK {
+				analysis.Unordered = append(analysis.Unordered, [2]int{closure.pages[i], closure.pages[j]})
+			}
+		}
+	}
+
+	if len(analysis.Cycles) > 0 {
+		return analysis
+	}
+
+	// Without cycles a rule a|b is implied by the others exactly when b is
+	// reachable from another of a's successors, since no path from there can
+	// lead back through a
+	viaSuccessors := make([]bitset, n)
+	for v, successors := range closure.successors {
+		viaSuccessors[v] = newBitset(n)
+		for _, w := range successors {
+			viaSuccessors[v].union(closure.reach[w])
+		}
+	}
+	seen := make(map[OrderingRule]bool)
+	for _, rule := range rules {
+		from, to := closure.index[rule.Before], closure.index[rule.After]
+		if seen[rule] || viaSuccessors[from].has(to) {
+			analysis.RedundantCount++
+			if len(analysis.Redundant) < topK {
+				analysis.Redundant = append(analysis.Redundant, rule)
+			}
+		}
+		seen[rule] = true
+	}
+
+	if analysis.UnorderedCount == 0 {
+		// In a total order each page comes before all the pages after it, so
+		// sorting by how many pages each one precedes gives the order
+		order := make([]int, n)
+		precedes := make([]int, n)
+		for i := range order {
+			order[i], precedes[i] = i, closure.reach[i].count()
+		}
+		sort.Slice(order, func(i, j int) bool { return precedes[order[i]] > precedes[order[j]] })
+
+		analysis.TotalOrder = make([]int, n)
+		for i, v := range order {
+			analysis.TotalOrder[i] = closure.pages[v]
+		}
+	}
+	return analysis
+}
+
+// WriteTable writes the analysis with a verdict on whether a total order exists
+func (a *RuleAnalysis) WriteTable(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%d rules over %d pages imply %d ordered pairs\n", a.Rules, a.Pages, a.Implied)
+
+	fmt.Fprintf(&b, "\nCycles (%d)\n", len(a.Cycles))
+	for _, cycle := range a.Cycles {
+		b.WriteString("  " + (&CycleError{Cycle: cycle}).pagesString() + "\n")
+	}
+
+	if len(a.Cycles) == 0 {
+		fmt.Fprintf(&b, "\nRedundant rules (%d of %d)\n", len(a.Redundant), a.RedundantCount)
+		for _, rule := range a.Redundant {
+			fmt.Fprintf(&b, "  %d|%d\n", rule.Before, rule.After)
+		}
+	}
+
+	fmt.Fprintf(&b, "\nUnordered pairs (%d of %d)\n", len(a.Unordered), a.UnorderedCount)
+	for _, pair := range a.Unordered {
+		fmt.Fprintf(&b, "  %d %d\n", pair[0], pair[1])
+	}
+
+	b.WriteString("\n")
+	switch {
+	case len(a.Cycles) > 0:
+		b.WriteString("No total order: pages on a cycle must come before themselves\n")
+	case a.UnorderedCount > 0:
+		fmt.Fprintf(&b, "No total order: %d pairs of pages are unordered\n", a.UnorderedCount)
+	default:
+		pages := make([]string, len(a.TotalOrder))
+		for i, page := range a.TotalOrder {
+			pages[i] = fmt.Sprint(page)
+		}
+		fmt.Fprintf(&b, "Total order: %s\n", strings.Join(pages, ","))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
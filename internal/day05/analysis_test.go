@@ -0,0 +1,139 @@
+package day05
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// bruteReach follows rules breadth-first from before
+func bruteReach(rules []OrderingRule, before, after int) bool {
+	seen := map[int]bool{}
+	queue := []int{before}
+	for len(queue) > 0 {
+		page := queue[0]
+		queue = queue[1:]
+		for _, rule := range rules {
+			if rule.Before == page && !seen[rule.After] {
+				if rule.After == after {
+					return true
+				}
+				seen[rule.After] = true
+				queue = append(queue, rule.After)
+			}
+		}
+	}
+	return false
+}
+
+func TestClosureMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(9))
+
+	for trial := 0; trial < 100; trial++ {
+		pages := 2 + rng.Intn(10)
+		var rules []OrderingRule
+		for i := rng.Intn(20); i > 0; i-- {
+			rules = append(rules, OrderingRule{Before: rng.Intn(pages), After: rng.Intn(pages)})
+		}
+
+		closure := NewClosure(rules)
+		for before := 0; before < pages; before++ {
+			for after := 0; after < pages; after++ {
+				if got, expected := closure.Implies(before, after), bruteReach(rules, before, after); got != expected {
+					t.Fatalf("Implies(%d, %d) = %v, expected %v for rules %v", before, after, got, expected, rules)
+				}
+			}
+		}
+
+		for _, cycle := range closure.Cycles() {
+			assertCycleFollowsRules(t, cycle, rules)
+		}
+	}
+}
+
+func TestAnalyseRulesExample(t *testing.T) {
+	input := readExample(t)
+
+	analysis := AnalyseRules(input.Rules, 100)
+	if analysis.Pages != 7 || analysis.Rules != 21 || analysis.Implied != 21 {
+		t.Errorf("AnalyseRules() = %+v, expected 21 rules over 7 pages", analysis)
+	}
+	if len(analysis.Cycles) != 0 || analysis.UnorderedCount != 0 {
+		t.Errorf("AnalyseRules() found cycles %v and %d unordered pairs, expected none", analysis.Cycles, analysis.UnorderedCount)
+	}
+	// Every rule but the six between neighbours in the order is implied
+	if analysis.RedundantCount != 15 || len(analysis.Redundant) != 15 {
+		t.Errorf("AnalyseRules() found %d redundant rules, expected 15", analysis.RedundantCount)
+	}
+
+	expected := []int{97, 75, 47, 61, 53, 29, 13}
+	if len(analysis.TotalOrder) != len(expected) {
+		t.Fatalf("TotalOrder = %v, expected %v", analysis.TotalOrder, expected)
+	}
+	for i := range expected {
+		if analysis.TotalOrder[i] != expected[i] {
+			t.Fatalf("TotalOrder = %v, expected %v", analysis.TotalOrder, expected)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := analysis.WriteTable(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(out.String(), "Total order: 97,75,47,61,53,29,13\n") {
+		t.Errorf("WriteTable() output:\n%s", out.String())
+	}
+}
+
+func TestAnalyseRules(t *testing.T) {
+	tests := []struct {
+		name       string
+		rules      []OrderingRule
+		cycles     [][]int
+		redundant  int
+		unordered  int
+		totalOrder []int
+	}{
+		{"chain", []OrderingRule{{1, 2}, {2, 3}}, nil, 0, 0, []int{1, 2, 3}},
+		{"shortcut", []OrderingRule{{1, 2}, {2, 3}, {1, 3}}, nil, 1, 0, []int{1, 2, 3}},
+		{"duplicate", []OrderingRule{{1, 2}, {1, 2}}, nil, 1, 0, []int{1, 2}},
+		{"two chains", []OrderingRule{{1, 2}, {3, 4}}, nil, 0, 4, nil},
+		{"cycles", []OrderingRule{{2, 3}, {3, 1}, {1, 2}, {4, 4}, {1, 4}}, [][]int{{1, 2, 3}, {4}}, 0, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis := AnalyseRules(tt.rules, 10)
+			if len(analysis.Cycles) != len(tt.cycles) {
+				t.Fatalf("Cycles = %v, expected %v", analysis.Cycles, tt.cycles)
+			}
+			for i, cycle := range tt.cycles {
+				if !sameCycle(analysis.Cycles[i], cycle) {
+					t.Errorf("Cycles[%d] = %v, expected %v", i, analysis.Cycles[i], cycle)
+				}
+			}
+			if analysis.RedundantCount != tt.redundant {
+				t.Errorf("RedundantCount = %d, expected %d", analysis.RedundantCount, tt.redundant)
+			}
+			if analysis.UnorderedCount != tt.unordered {
+				t.Errorf("UnorderedCount = %d, expected %d", analysis.UnorderedCount, tt.unordered)
+			}
+			if len(analysis.TotalOrder) != len(tt.totalOrder) {
+				t.Fatalf("TotalOrder = %v, expected %v", analysis.TotalOrder, tt.totalOrder)
+			}
+			for i := range tt.totalOrder {
+				if analysis.TotalOrder[i] != tt.totalOrder[i] {
+					t.Errorf("TotalOrder = %v, expected %v", analysis.TotalOrder, tt.totalOrder)
+					break
+				}
+			}
+		})
+	}
+
+	// topK limits the lists but not the counts
+	analysis := AnalyseRules([]OrderingRule{{1, 2}, {3, 4}}, 1)
+	if len(analysis.Unordered) != 1 || analysis.UnorderedCount != 4 {
+		t.Errorf("AnalyseRules(topK 1) listed %v of %d unordered pairs", analysis.Unordered, analysis.UnorderedCount)
+	}
+}
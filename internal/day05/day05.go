@@ -3,14 +3,15 @@ package day05
 import (
 	"fmt"
 	"os"
-	"sort"
 	"strconv"
 	"strings"
+
+	"advent-of-code-2024/internal/trace"
 )
 
 type OrderingRule struct {
-	Before int
-	After  int
+	Before int `json:"before"`
+	After  int `json:"after"`
 }
 
 type Update []int
@@ -87,106 +88,139 @@ func ParseInput(content string) (PuzzleInput, error) {
 	return PuzzleInput{Rules: rules, Updates: updates}, nil
 }
 
-func IsValidUpdate(update Update, rules []OrderingRule) bool {
-	// Create a map for quick lookup of page positions
-	pagePos := make(map[int]int)
-	for i, page := range update {
-		pagePos[page] = i
-	}
-
-	// Check each rule against the update
-	for _, rule := range rules {
-		beforePos, beforeExists := pagePos[rule.Before]
-		afterPos, afterExists := pagePos[rule.After]
-
-		// Only check rules where both pages are present in the update
-		if beforeExists && afterExists {
-			// Rule violation if "before" page comes after "after" page
-			if beforePos >= afterPos {
-				return false
-			}
-		}
+// readInput reads and parses the puzzle input in filename
+func readInput(filename string) (PuzzleInput, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return PuzzleInput{}, err
 	}
 
-	return true
+	return ParseInput(string(content))
 }
 
-func GetMiddlePage(update Update) int {
-	// For odd-length slices, middle is at index len/2
-	return update[len(update)/2]
+// IsValidUpdate reports whether update breaks none of rules. To check many
+// updates against the same rules, build a RuleSet once instead.
+func IsValidUpdate(update Update, rules []OrderingRule) bool {
+	return NewRuleSet(rules).IsValid(update)
 }
 
-func FixUpdateOrder(update Update, rules []OrderingRule) Update {
-	// Create a copy of the update to avoid modifying the original
-	fixed := make(Update, len(update))
-	copy(fixed, update)
-
-	// Build a map of rules for quick lookup
-	ruleMap := make(map[[2]int]bool)
-	for _, rule := range rules {
-		ruleMap[[2]int{rule.Before, rule.After}] = true
+func GetMiddlePage(update Update) (int, error) {
+	if len(update) == 0 {
+		return 0, fmt.Errorf("empty update has no middle page")
 	}
 
-	// Sort using custom comparator based on rules
-	sort.Slice(fixed, func(i, j int) bool {
-		pageA, pageB := fixed[i], fixed[j]
+	// For odd-length slices, middle is at index len/2
+	return update[len(update)/2], nil
+}
 
-		// Check if there's a direct rule A|B (A should come before B)
-		if ruleMap[[2]int{pageA, pageB}] {
-			return true
-		}
+// CycleError reports rules that require a page to come before itself, so no
+// order can satisfy them
+type CycleError struct {
+	Cycle []int // each page must come before the next, and the last before the first
+}
 
-		// Check if there's a rule B|A (B should come before A)
-		if ruleMap[[2]int{pageB, pageA}] {
-			return false
-		}
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("rules form a cycle: %s", e.pagesString())
+}
 
-		// No direct rule found, maintain original relative order
-		return i < j
-	})
+// pagesString writes the cycle as pages joined by arrows, back to the first
+func (e *CycleError) pagesString() string {
+	pages := make([]string, len(e.Cycle)+1)
+	for i, page := range e.Cycle {
+		pages[i] = strconv.Itoa(page)
+	}
+	pages[len(e.Cycle)] = pages[0]
+	return strings.Join(pages, " -> ")
+}
 
-	return fixed
+// FixUpdateOrder puts the pages of update into an order that satisfies every
+// rule between them, using Kahn's algorithm over the rules restricted to those
+// pages. Pages with no rule between them keep their original relative order.
+// If the restricted rules contain a cycle it returns a *CycleError listing it.
+// To fix many updates against the same rules, build a RuleSet once instead.
+func FixUpdateOrder(update Update, rules []OrderingRule) (Update, error) {
+	return NewRuleSet(rules).FixOrder(update)
 }
 
-func SolvePart1(filename string) (int, error) {
-	content, err := os.ReadFile(filename)
-	if err != nil {
-		return 0, err
+// findCycle returns the pages of a cycle among the positions not yet placed.
+// Each of them still has a predecessor that is not placed, so walking back
+// along those must eventually revisit a position.
+func findCycle(update Update, successors [][]int, placed []bool) []int {
+	predecessor := make([]int, len(update))
+	for from, tos := range successors {
+		if placed[from] {
+			continue
+		}
+		for _, to := range tos {
+			predecessor[to] = from
+		}
 	}
 
-	input, err := ParseInput(string(content))
-	if err != nil {
-		return 0, err
+	start := 0
+	for placed[start] {
+		start++
 	}
 
-	sum := 0
-	for _, update := range input.Updates {
-		if IsValidUpdate(update, input.Rules) {
-			sum += GetMiddlePage(update)
+	seen := make(map[int]int) // position -> step it was reached at
+	var walk []int
+	for current := start; ; current = predecessor[current] {
+		if step, ok := seen[current]; ok {
+			walk = walk[step:]
+			break
 		}
+		seen[current] = len(walk)
+		walk = append(walk, current)
 	}
 
-	return sum, nil
+	// The walk went against the rules, so reverse it to read in rule order
+	cycle := make([]int, len(walk))
+	for i, position := range walk {
+		cycle[len(walk)-1-i] = update[position]
+	}
+	return cycle
 }
 
-func SolvePart2(filename string) (int, error) {
-	content, err := os.ReadFile(filename)
-	if err != nil {
-		return 0, err
+// traceUpdate reports whether an update is valid and every rule it breaks
+func traceUpdate(index int, update Update, rules *RuleSet) {
+	if !trace.Enabled() {
+		return
 	}
 
-	input, err := ParseInput(string(content))
+	violations := rules.Violations(update)
+	for _, v := range violations {
+		trace.Emit("violation", fmt.Sprintf("update %d breaks rule %d|%d: %d is at position %d but %d is at position %d",
+			index+1, v.Rule.Before, v.Rule.After, v.Rule.Before, v.BeforePos, v.Rule.After, v.AfterPos),
+			map[string]any{"update": index, "before": v.Rule.Before, "after": v.Rule.After, "beforePos": v.BeforePos, "afterPos": v.AfterPos})
+	}
+
+	if len(violations) == 0 {
+		trace.Emit("valid", fmt.Sprintf("update %d %v is in the right order", index+1, update),
+			map[string]any{"update": index, "pages": update})
+	}
+}
+
+func SolvePart1(filename string) (int, error) {
+	input, err := readInput(filename)
 	if err != nil {
 		return 0, err
 	}
 
+	rules := NewRuleSet(input.Rules)
 	sum := 0
-	for _, update := range input.Updates {
-		if !IsValidUpdate(update, input.Rules) {
-			fixed := FixUpdateOrder(update, input.Rules)
-			sum += GetMiddlePage(fixed)
+	for i, update := range input.Updates {
+		traceUpdate(i, update, rules)
+		if rules.IsValid(update) {
+			middle, err := GetMiddlePage(update)
+			if err != nil {
+				return 0, err
+			}
+			sum += middle
 		}
 	}
 
 	return sum, nil
 }
+
+func SolvePart2(filename string) (int, error) {
+	return SolvePart2With(filename, RepairReorder)
+}
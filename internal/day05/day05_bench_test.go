@@ -0,0 +1,126 @@
+package day05
+
+import (
+	"os"
+	"testing"
+
+	"advent-of-code-2024/internal/gen"
+)
+
+// benchmarkPages is the number of pages in the generated input. Every pair of
+// pages has a rule, so this gives 319,600 rules and 3,200 updates.
+const benchmarkPages = 800
+
+func benchmarkInput(b *testing.B) (PuzzleInput, string) {
+	content, err := gen.String(5, benchmarkPages, 1)
+	if err != nil {
+		b.Fatal(err)
+	}
+	input, err := ParseInput(content)
+	if err != nil {
+		b.Fatal(err)
+	}
+	path := b.TempDir() + "/input.txt"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		b.Fatal(err)
+	}
+	return input, path
+}
+
+func BenchmarkNewRuleSet(b *testing.B) {
+	input, _ := benchmarkInput(b)
+	wide, _ := spread(input.Rules, nil)
+
+	b.Run("dense", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			NewRuleSet(input.Rules)
+		}
+	})
+	b.Run("sparse", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			NewRuleSet(wide)
+		}
+	})
+}
+
+// Benchmark checking every update against one rule set
+func BenchmarkRuleSetIsValid(b *testing.B) {
+	input, _ := benchmarkInput(b)
+	wide, _ := spread(input.Rules, nil)
+	wideUpdates := make([]Update, len(input.Updates))
+	for i, update := range input.Updates {
+		_, wideUpdates[i] = spread(nil, update)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		rules   *RuleSet
+		updates []Update
+	}{
+		{"dense", NewRuleSet(input.Rules), input.Updates},
+		{"sparse", NewRuleSet(wide), wideUpdates},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, update := range tc.updates {
+					tc.rules.IsValid(update)
+				}
+			}
+		})
+	}
+}
+
+// violationUpdates is how many updates the Violations benchmarks check, since
+// scanning every rule for all of them takes tens of seconds
+const violationUpdates = 100
+
+// Benchmark the old approach of scanning every rule for each update
+func BenchmarkNaiveViolations(b *testing.B) {
+	input, _ := benchmarkInput(b)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, update := range input.Updates[:violationUpdates] {
+			naiveViolations(update, input.Rules)
+		}
+	}
+}
+
+func BenchmarkRuleSetViolations(b *testing.B) {
+	input, _ := benchmarkInput(b)
+	rules := NewRuleSet(input.Rules)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, update := range input.Updates[:violationUpdates] {
+			rules.Violations(update)
+		}
+	}
+}
+
+func BenchmarkSolveBothParts(b *testing.B) {
+	_, path := benchmarkInput(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := SolvePart1(path); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := SolvePart2(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAnalyseRules(b *testing.B) {
+	input, _ := benchmarkInput(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		AnalyseRules(input.Rules, 10)
+	}
+}
@@ -0,0 +1,89 @@
+package day05
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"testing"
+)
+
+func FuzzParseInput(f *testing.F) {
+	example, err := os.ReadFile("example-input.txt")
+	if err != nil {
+		f.Fatalf("failed to read example input: %v", err)
+	}
+	f.Add(string(example))
+	f.Add("")
+	f.Add("1|2\n\n")
+	f.Add("1|2|3\n\n1,2")
+	f.Add("1|2\n\n2,,1")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		input, err := ParseInput(data)
+		if err != nil {
+			return
+		}
+		for i, update := range input.Updates {
+			if len(update) == 0 {
+				t.Fatalf("update %d has no pages", i)
+			}
+		}
+	})
+}
+
+func FuzzIsValidUpdate(f *testing.F) {
+	example, err := os.ReadFile("example-input.txt")
+	if err != nil {
+		f.Fatalf("failed to read example input: %v", err)
+	}
+	f.Add(string(example))
+	f.Add("1|2\n2|1\n\n1,2")
+	f.Add("5|5\n\n5,5,5")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		input, err := ParseInput(data)
+		if err != nil {
+			return
+		}
+
+		for _, update := range input.Updates {
+			_ = IsValidUpdate(update, input.Rules)
+
+			fixed, err := FixUpdateOrder(update, input.Rules)
+			if err != nil {
+				var cycleErr *CycleError
+				if !errors.As(err, &cycleErr) {
+					t.Fatalf("FixUpdateOrder(%v) error = %v, expected a *CycleError", update, err)
+				}
+				assertCycleFollowsRules(t, cycleErr.Cycle, input.Rules)
+				continue
+			}
+			if !IsValidUpdate(fixed, input.Rules) {
+				t.Fatalf("FixUpdateOrder(%v) = %v breaks a rule", update, fixed)
+			}
+			if !samePages(update, fixed) {
+				t.Fatalf("FixUpdateOrder(%v) = %v is not a permutation", update, fixed)
+			}
+
+			if _, err := GetMiddlePage(fixed); err != nil {
+				t.Fatalf("GetMiddlePage(%v) returned error for parsed update: %v", fixed, err)
+			}
+		}
+	})
+}
+
+func samePages(a, b Update) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sortedA := append(Update(nil), a...)
+	sortedB := append(Update(nil), b...)
+	sort.Ints(sortedA)
+	sort.Ints(sortedB)
+	for i := range sortedA {
+		if sortedA[i] != sortedB[i] {
+			return false
+		}
+	}
+	return true
+}
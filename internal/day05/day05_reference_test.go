@@ -0,0 +1,95 @@
+package day05
+
+import (
+	"fmt"
+	"testing"
+
+	"advent-of-code-2024/internal/difftest"
+)
+
+// referenceValid checks every pair of pages against every rule
+func referenceValid(update Update, rules []OrderingRule) bool {
+	for i := range update {
+		for j := i + 1; j < len(update); j++ {
+			for _, rule := range rules {
+				if rule.Before == update[j] && rule.After == update[i] {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// referenceOrder repeatedly takes the first remaining page that no other
+// remaining page is required to precede
+func referenceOrder(update Update, rules []OrderingRule) (Update, error) {
+	remaining := append(Update(nil), update...)
+	var ordered Update
+
+	for len(remaining) > 0 {
+		next := -1
+		for i, candidate := range remaining {
+			blocked := false
+			for j, other := range remaining {
+				for _, rule := range rules {
+					if i != j && rule.Before == other && rule.After == candidate {
+						blocked = true
+					}
+				}
+			}
+			if !blocked {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			return nil, fmt.Errorf("no valid order for %v", update)
+		}
+		ordered = append(ordered, remaining[next])
+		remaining = append(remaining[:next], remaining[next+1:]...)
+	}
+	return ordered, nil
+}
+
+// The reference solvers share ParseInput; only the ordering logic is under test
+func referencePart1(input string) (int, error) {
+	parsed, err := ParseInput(input)
+	if err != nil {
+		return 0, err
+	}
+
+	sum := 0
+	for _, update := range parsed.Updates {
+		if referenceValid(update, parsed.Rules) {
+			sum += update[len(update)/2]
+		}
+	}
+	return sum, nil
+}
+
+func referencePart2(input string) (int, error) {
+	parsed, err := ParseInput(input)
+	if err != nil {
+		return 0, err
+	}
+
+	sum := 0
+	for _, update := range parsed.Updates {
+		if referenceValid(update, parsed.Rules) {
+			continue
+		}
+		ordered, err := referenceOrder(update, parsed.Rules)
+		if err != nil {
+			return 0, err
+		}
+		sum += ordered[len(ordered)/2]
+	}
+	return sum, nil
+}
+
+func TestDifferential(t *testing.T) {
+	difftest.Run(t, 5, []int{2, 7, 30},
+		difftest.Part{Solve: SolvePart1, Reference: referencePart1},
+		difftest.Part{Solve: SolvePart2, Reference: referencePart2})
+}
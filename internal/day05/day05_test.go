@@ -1,8 +1,12 @@
 package day05
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
 	"testing"
+
+	"advent-of-code-2024/internal/trace"
 )
 
 func TestParseRule(t *testing.T) {
@@ -136,13 +140,22 @@ func TestGetMiddlePage(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		result := GetMiddlePage(test.update)
+		result, err := GetMiddlePage(test.update)
+		if err != nil {
+			t.Errorf("GetMiddlePage(%v) returned error: %v", test.update, err)
+		}
 		if result != test.expected {
 			t.Errorf("GetMiddlePage(%v) = %d, expected %d", test.update, result, test.expected)
 		}
 	}
 }
 
+func TestGetMiddlePageEmpty(t *testing.T) {
+	if _, err := GetMiddlePage(Update{}); err == nil {
+		t.Error("GetMiddlePage(Update{}) should return error for empty update")
+	}
+}
+
 func TestSolvePart1WithExampleFile(t *testing.T) {
 	content, err := os.ReadFile("example-input.txt")
 	if err != nil {
@@ -159,7 +172,11 @@ func TestSolvePart1WithExampleFile(t *testing.T) {
 	sum := 0
 	for _, update := range input.Updates {
 		if IsValidUpdate(update, input.Rules) {
-			sum += GetMiddlePage(update)
+			middle, err := GetMiddlePage(update)
+			if err != nil {
+				t.Fatalf("GetMiddlePage(%v) returned error: %v", update, err)
+			}
+			sum += middle
 		}
 	}
 
@@ -205,7 +222,11 @@ func TestFixUpdateOrder(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		result := FixUpdateOrder(test.input, rules)
+		result, err := FixUpdateOrder(test.input, rules)
+		if err != nil {
+			t.Errorf("FixUpdateOrder(%v) error = %v (%s)", test.input, err, test.desc)
+			continue
+		}
 		if len(result) != len(test.expected) {
 			t.Errorf("FixUpdateOrder(%v) length = %d, expected %d (%s)", test.input, len(result), len(test.expected), test.desc)
 			continue
@@ -218,6 +239,111 @@ func TestFixUpdateOrder(t *testing.T) {
 	}
 }
 
+func TestFixUpdateOrderKeepsUnconstrainedOrder(t *testing.T) {
+	// Only 3|1 applies, so 5 and 4 stay where they were relative to each other
+	rules := []OrderingRule{{Before: 3, After: 1}, {Before: 9, After: 5}}
+
+	result, err := FixUpdateOrder(Update{5, 1, 4, 3}, rules)
+	if err != nil {
+		t.Fatalf("FixUpdateOrder() error = %v", err)
+	}
+	expected := Update{5, 4, 3, 1}
+	for i, page := range expected {
+		if result[i] != page {
+			t.Fatalf("FixUpdateOrder() = %v, expected %v", result, expected)
+		}
+	}
+
+	// Both copies of a repeated page must come before the page it precedes
+	result, err = FixUpdateOrder(Update{6, 8, 6}, []OrderingRule{{Before: 6, After: 8}})
+	if err != nil {
+		t.Fatalf("FixUpdateOrder() error = %v", err)
+	}
+	if result[0] != 6 || result[1] != 6 || result[2] != 8 {
+		t.Errorf("FixUpdateOrder() = %v, expected [6 6 8]", result)
+	}
+}
+
+func TestFixUpdateOrderCycle(t *testing.T) {
+	tests := []struct {
+		name   string
+		update Update
+		rules  []OrderingRule
+		cycle  []int
+	}{
+		{"two pages", Update{1, 2}, []OrderingRule{{1, 2}, {2, 1}}, []int{1, 2}},
+		{"three pages", Update{4, 1, 2, 3}, []OrderingRule{{1, 2}, {2, 3}, {3, 1}, {4, 1}}, []int{1, 2, 3}},
+		{"self rule", Update{7, 5}, []OrderingRule{{5, 5}}, []int{5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := FixUpdateOrder(tt.update, tt.rules)
+			var cycleErr *CycleError
+			if !errors.As(err, &cycleErr) {
+				t.Fatalf("FixUpdateOrder() error = %v, expected a *CycleError", err)
+			}
+			if !sameCycle(cycleErr.Cycle, tt.cycle) {
+				t.Errorf("CycleError.Cycle = %v, expected a rotation of %v", cycleErr.Cycle, tt.cycle)
+			}
+			assertCycleFollowsRules(t, cycleErr.Cycle, tt.rules)
+		})
+	}
+
+	err := &CycleError{Cycle: []int{1, 2, 3}}
+	if got := err.Error(); got != "rules form a cycle: 1 -> 2 -> 3 -> 1" {
+		t.Errorf("Error() = %q", got)
+	}
+}
+
+// sameCycle reports whether a is b started from a different page
+func sameCycle(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for shift := range b {
+		match := true
+		for i := range a {
+			if a[i] != b[(i+shift)%len(b)] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// assertCycleFollowsRules checks there is a rule from each page of cycle to the next
+func assertCycleFollowsRules(t *testing.T, cycle []int, rules []OrderingRule) {
+	t.Helper()
+	for i, page := range cycle {
+		next := cycle[(i+1)%len(cycle)]
+		found := false
+		for _, rule := range rules {
+			found = found || rule == OrderingRule{Before: page, After: next}
+		}
+		if !found {
+			t.Errorf("cycle %v has no rule %d|%d", cycle, page, next)
+		}
+	}
+}
+
+func TestSolvePart2Cycle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.txt")
+	if err := os.WriteFile(path, []byte("1|2\n2|3\n3|1\n\n3,2,1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := SolvePart2(path)
+	var cycleErr *CycleError
+	if !errors.As(err, &cycleErr) {
+		t.Errorf("SolvePart2() error = %v, expected a *CycleError", err)
+	}
+}
+
 func TestSolvePart2WithExampleFile(t *testing.T) {
 	content, err := os.ReadFile("example-input.txt")
 	if err != nil {
@@ -234,8 +360,15 @@ func TestSolvePart2WithExampleFile(t *testing.T) {
 	sum := 0
 	for _, update := range input.Updates {
 		if !IsValidUpdate(update, input.Rules) {
-			fixed := FixUpdateOrder(update, input.Rules)
-			sum += GetMiddlePage(fixed)
+			fixed, err := FixUpdateOrder(update, input.Rules)
+			if err != nil {
+				t.Fatalf("FixUpdateOrder(%v) returned error: %v", update, err)
+			}
+			middle, err := GetMiddlePage(fixed)
+			if err != nil {
+				t.Fatalf("GetMiddlePage(%v) returned error: %v", fixed, err)
+			}
+			sum += middle
 		}
 	}
 
@@ -244,3 +377,37 @@ func TestSolvePart2WithExampleFile(t *testing.T) {
 		t.Errorf("Part2 logic = %d, expected %d", sum, expected)
 	}
 }
+
+func TestTraceEvents(t *testing.T) {
+	var events []trace.Event
+	trace.SetHook(func(e trace.Event) { events = append(events, e) })
+	defer trace.SetHook(nil)
+
+	if _, err := SolvePart2("example-input.txt"); err != nil {
+		t.Fatalf("SolvePart2 returned error: %v", err)
+	}
+
+	kinds := make(map[string]int)
+	for _, event := range events {
+		kinds[event.Kind]++
+	}
+
+	if kinds["valid"] != 3 {
+		t.Errorf("Expected 3 valid events, got %d", kinds["valid"])
+	}
+	if kinds["fixed"] != 3 {
+		t.Errorf("Expected 3 fixed events, got %d", kinds["fixed"])
+	}
+
+	// 75,97,47,61,53 only breaks 97|75
+	expected := "update 4 breaks rule 97|75: 97 is at position 1 but 75 is at position 0"
+	found := false
+	for _, event := range events {
+		if event.Message == expected {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected event %q", expected)
+	}
+}
@@ -0,0 +1,120 @@
+package day05
+
+// largestKept finds the most positions of update that can stay where they are
+// while the others move, along with which positions the rules, followed through
+// the update's pages, put before which.
+//
+// Say positions i < j are inverted when the rules need update[j] before
+// update[i]. Inversion is transitive, so the pages that can stay where they are
+// form an antichain of it, and any antichain can stay: with no inverted pair
+// among them, some valid order keeps them in their original order. The largest
+// antichain, found from a maximum matching by Dilworth's and König's theorems,
+// leaves the fewest pages to move.
+func (rs *RuleSet) largestKept(update Update) ([][]bool, []bool, error) {
+	k := len(update)
+
+	// Follow the rules between the update's positions through every chain
+	precedes := make([][]bool, k)
+	for i := range precedes {
+		precedes[i] = make([]bool, k)
+		for j := range precedes[i] {
+			precedes[i][j] = rs.Requires(update[i], update[j])
+		}
+	}
+	for via := 0; via < k; via++ {
+		for i := 0; i < k; i++ {
+			if !precedes[i][via] {
+				continue
+			}
+			for j := 0; j < k; j++ {
+				precedes[i][j] = precedes[i][j] || precedes[via][j]
+			}
+		}
+	}
+	for i := 0; i < k; i++ {
+		if precedes[i][i] {
+			// FixOrder finds and reports the cycle
+			return nil, nil, cycleIn(rs, update)
+		}
+	}
+
+	inverted := func(i, j int) bool { return i < j && precedes[j][i] }
+	return precedes, maximumAntichain(k, inverted), nil
+}
+
+// cycleIn returns the *CycleError FixOrder reports for update
+func cycleIn(rs *RuleSet, update Update) error {
+	_, err := rs.FixOrder(update)
+	return err
+}
+
+// maximumAntichain returns the largest set of elements 0..n-1 with no two
+// related by less, which must be a strict partial order. By Dilworth's theorem
+// it is as large as the fewest chains covering every element, which is n less a
+// maximum matching between each element and the ones above it; König's theorem
+// turns a minimum vertex cover of that matching graph into the antichain.
+func maximumAntichain(n int, less func(i, j int) bool) []bool {
+	above := make([][]int, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if less(i, j) {
+				above[i] = append(above[i], j)
+			}
+		}
+	}
+
+	// Kuhn's augmenting paths: matchLeft[i] is the j matched above i
+	matchLeft, matchRight := make([]int, n), make([]int, n)
+	for i := range matchLeft {
+		matchLeft[i], matchRight[i] = -1, -1
+	}
+	var visited []bool
+	var augment func(i int) bool
+	augment = func(i int) bool {
+		for _, j := range above[i] {
+			if visited[j] {
+				continue
+			}
+			visited[j] = true
+			if matchRight[j] < 0 || augment(matchRight[j]) {
+				matchLeft[i], matchRight[j] = j, i
+				return true
+			}
+		}
+		return false
+	}
+	for i := 0; i < n; i++ {
+		visited = make([]bool, n)
+		augment(i)
+	}
+
+	// From each unmatched left element, follow unmatched edges right and
+	// matched edges back left. The cover is the left elements not reached and
+	// the right elements reached.
+	reachedLeft, reachedRight := make([]bool, n), make([]bool, n)
+	var walk func(i int)
+	walk = func(i int) {
+		reachedLeft[i] = true
+		for _, j := range above[i] {
+			if reachedRight[j] {
+				continue
+			}
+			reachedRight[j] = true
+			if matchRight[j] >= 0 && !reachedLeft[matchRight[j]] {
+				walk(matchRight[j])
+			}
+		}
+	}
+	for i := 0; i < n; i++ {
+		if matchLeft[i] < 0 && !reachedLeft[i] {
+			walk(i)
+		}
+	}
+
+	// An element is in the antichain when neither of its copies is covered
+	antichain := make([]bool, n)
+	for i := 0; i < n; i++ {
+		antichain[i] = reachedLeft[i] && !reachedRight[i]
+	}
+	return antichain
+}
@@ -0,0 +1,284 @@
+package day05
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"advent-of-code-2024/internal/trace"
+)
+
+// RepairStrategy chooses how part 2 puts an invalid update in order
+type RepairStrategy string
+
+const (
+	RepairReorder RepairStrategy = "reorder" // FixOrder: sort every page by the rules
+	RepairMinimal RepairStrategy = "minimal" // move as few pages as possible
+)
+
+// ParseRepairStrategy reads a strategy name
+func ParseRepairStrategy(name string) (RepairStrategy, error) {
+	switch strategy := RepairStrategy(name); strategy {
+	case RepairReorder, RepairMinimal:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unknown repair strategy %q, expected %s or %s", name, RepairReorder, RepairMinimal)
+	}
+}
+
+// Move takes the page at From out of the update and puts it back so that it
+// ends up at To. Positions count from 0 in the update as it is just before
+// and just after the move.
+type Move struct {
+	Page int `json:"page"`
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// Repair is an update put in order, and the moves that get there from the
+// original one after another
+type Repair struct {
+	Fixed Update `json:"fixed"`
+	Moves []Move `json:"moves"`
+}
+
+// RepairUpdate puts update in order with as few moves as possible
+func RepairUpdate(update Update, rules []OrderingRule) (Repair, error) {
+	return NewRuleSet(rules).RepairMinimal(update)
+}
+
+// RepairMinimal puts update in order moving as few pages as possible
+func (rs *RuleSet) RepairMinimal(update Update) (Repair, error) {
+	k := len(update)
+	precedes, keep, err := rs.largestKept(update)
+	if err != nil {
+		return Repair{}, err
+	}
+
+	// Order the pages by the rules with the kept pages chained in their
+	// original order, taking the earliest ready position each time
+	inDegree := make([]int, k)
+	for i := 0; i < k; i++ {
+		for j := 0; j < k; j++ {
+			if precedes[i][j] {
+				inDegree[j]++
+			}
+		}
+	}
+	var kept []int
+	for i := 0; i < k; i++ {
+		if keep[i] {
+			kept = append(kept, i)
+		}
+	}
+	for n := 1; n < len(kept); n++ {
+		inDegree[kept[n]]++
+	}
+	nextKept := make(map[int]int, len(kept))
+	for n := 0; n+1 < len(kept); n++ {
+		nextKept[kept[n]] = kept[n+1]
+	}
+
+	order := make([]int, 0, k)
+	placed := make([]bool, k)
+	for len(order) < k {
+		next := -1
+		for i := 0; i < k; i++ {
+			if !placed[i] && inDegree[i] == 0 {
+				next = i
+				break
+			}
+		}
+		placed[next] = true
+		order = append(order, next)
+		for j := 0; j < k; j++ {
+			if precedes[next][j] {
+				inDegree[j]--
+			}
+		}
+		if after, ok := nextKept[next]; ok {
+			inDegree[after]--
+		}
+	}
+
+	repair := Repair{Fixed: make(Update, k), Moves: movesTo(update, order, keep)}
+	for n, i := range order {
+		repair.Fixed[n] = update[i]
+	}
+	return repair, nil
+}
+
+// movesTo lists the moves that turn update into the pages at positions order,
+// moving only the positions not kept. Each moved page, taken in its final
+// order, goes straight after the page before it in order that is already
+// settled, so the settled pages always stand in their final relative order.
+func movesTo(update Update, order []int, keep []bool) []Move {
+	current := make([]int, len(update)) // original positions, as they now stand
+	for i := range current {
+		current[i] = i
+	}
+	settled := append([]bool(nil), keep...)
+
+	moves := []Move{}
+	for n, i := range order {
+		if keep[i] {
+			continue
+		}
+
+		from := indexOf(current, i)
+		current = append(current[:from], current[from+1:]...)
+
+		to := 0
+		for m := n - 1; m >= 0; m-- {
+			if settled[order[m]] {
+				to = indexOf(current, order[m]) + 1
+				break
+			}
+		}
+		current = append(current[:to], append([]int{i}, current[to:]...)...)
+		settled[i] = true
+
+		moves = append(moves, Move{Page: update[i], From: from, To: to})
+	}
+	return moves
+}
+
+func indexOf(values []int, value int) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
+
+// SolvePart2With solves part 2 putting each invalid update in order with
+// strategy. Both strategies give a valid order, but where the rules allow more
+// than one they may differ, and so may the middle pages.
+func SolvePart2With(filename string, strategy RepairStrategy) (int, error) {
+	input, err := readInput(filename)
+	if err != nil {
+		return 0, err
+	}
+
+	rules := NewRuleSet(input.Rules)
+	sum := 0
+	for i, update := range input.Updates {
+		traceUpdate(i, update, rules)
+		if rules.IsValid(update) {
+			continue
+		}
+
+		fixed, moves, err := rules.repair(update, strategy)
+		if err != nil {
+			return 0, fmt.Errorf("update %d: %w", i+1, err)
+		}
+		middle, err := GetMiddlePage(fixed)
+		if err != nil {
+			return 0, err
+		}
+		sum += middle
+
+		if trace.Enabled() {
+			for _, move := range moves {
+				trace.Emit("move", fmt.Sprintf("update %d moves page %d from position %d to %d", i+1, move.Page, move.From, move.To),
+					map[string]any{"update": i, "page": move.Page, "from": move.From, "to": move.To})
+			}
+			trace.Emit("fixed", fmt.Sprintf("update %d reordered to %v, middle page %d", i+1, fixed, middle),
+				map[string]any{"update": i, "pages": fixed, "middle": middle})
+		}
+	}
+
+	return sum, nil
+}
+
+// repair puts update in order with strategy, with the moves it took when the
+// strategy counts them
+func (rs *RuleSet) repair(update Update, strategy RepairStrategy) (Update, []Move, error) {
+	switch strategy {
+	case RepairReorder:
+		fixed, err := rs.FixOrder(update)
+		return fixed, nil, err
+	case RepairMinimal:
+		repair, err := rs.RepairMinimal(update)
+		return repair.Fixed, repair.Moves, err
+	default:
+		return nil, nil, fmt.Errorf("unknown repair strategy %q", strategy)
+	}
+}
+
+// UpdateRepair compares the two strategies on one invalid update
+type UpdateRepair struct {
+	Index   int    `json:"index"` // position of the update in the input, from 0
+	Update  Update `json:"update"`
+	Reorder Update `json:"reorder"`
+	Repair
+}
+
+// RepairReport shows how each invalid update is put in order with the fewest
+// moves, and the part 2 answer under both strategies
+type RepairReport struct {
+	Updates    []UpdateRepair `json:"updates"`
+	Moves      int            `json:"moves"`
+	ReorderSum int            `json:"reorderSum"`
+	MinimalSum int            `json:"minimalSum"`
+}
+
+// RepairFile reads the input in filename and repairs every invalid update
+func RepairFile(filename string) (*RepairReport, error) {
+	input, err := readInput(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := NewRuleSet(input.Rules)
+	report := &RepairReport{Updates: []UpdateRepair{}}
+	for i, update := range input.Updates {
+		if rules.IsValid(update) {
+			continue
+		}
+
+		reorder, err := rules.FixOrder(update)
+		if err != nil {
+			return nil, fmt.Errorf("update %d: %w", i+1, err)
+		}
+		repair, err := rules.RepairMinimal(update)
+		if err != nil {
+			return nil, fmt.Errorf("update %d: %w", i+1, err)
+		}
+
+		reorderMiddle, err := GetMiddlePage(reorder)
+		if err != nil {
+			return nil, fmt.Errorf("update %d: %w", i+1, err)
+		}
+		minimalMiddle, err := GetMiddlePage(repair.Fixed)
+		if err != nil {
+			return nil, fmt.Errorf("update %d: %w", i+1, err)
+		}
+
+		report.Updates = append(report.Updates, UpdateRepair{Index: i, Update: update, Reorder: reorder, Repair: repair})
+		report.Moves += len(repair.Moves)
+		report.ReorderSum += reorderMiddle
+		report.MinimalSum += minimalMiddle
+	}
+	return report, nil
+}
+
+// WriteTable writes each invalid update with its moves, then both part 2 sums
+func (r *RepairReport) WriteTable(w io.Writer) error {
+	var b strings.Builder
+
+	for _, u := range r.Updates {
+		fmt.Fprintf(&b, "update %d %v: %d moves to %v\n", u.Index+1, u.Update, len(u.Moves), u.Fixed)
+		for _, move := range u.Moves {
+			fmt.Fprintf(&b, "  move %d from %d to %d\n", move.Page, move.From, move.To)
+		}
+	}
+
+	fmt.Fprintf(&b, "\n%d invalid updates repaired with %d moves\n", len(r.Updates), r.Moves)
+	fmt.Fprintf(&b, "Part 2 with %-8s %d\n", RepairReorder, r.ReorderSum)
+	fmt.Fprintf(&b, "Part 2 with %-8s %d\n", RepairMinimal, r.MinimalSum)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
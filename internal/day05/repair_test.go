@@ -0,0 +1,176 @@
+package day05
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// applyMoves makes each move in turn on a copy of update
+func applyMoves(update Update, moves []Move) (Update, error) {
+	current := append(Update(nil), update...)
+	for _, move := range moves {
+		if move.From < 0 || move.From >= len(current) || current[move.From] != move.Page {
+			return nil, fmt.Errorf("page %d is not at position %d of %v", move.Page, move.From, current)
+		}
+		current = append(current[:move.From], current[move.From+1:]...)
+		if move.To < 0 || move.To > len(current) {
+			return nil, fmt.Errorf("position %d is outside %v", move.To, current)
+		}
+		current = append(current[:move.To], append(Update{move.Page}, current[move.To:]...)...)
+	}
+	return current, nil
+}
+
+// bruteMinimumMoves searches breadth-first over every single move until an
+// update satisfies rules, returning -1 if none does
+func bruteMinimumMoves(update Update, rules []OrderingRule) int {
+	seen := map[string]bool{fmt.Sprint(update): true}
+	level := []Update{update}
+	for moves := 0; len(level) > 0; moves++ {
+		var next []Update
+		for _, current := range level {
+			if IsValidUpdate(current, rules) {
+				return moves
+			}
+			for from := range current {
+				rest := append(append(Update(nil), current[:from]...), current[from+1:]...)
+				for to := 0; to <= len(rest); to++ {
+					moved := append(append(append(Update(nil), rest[:to]...), current[from]), rest[to:]...)
+					if key := fmt.Sprint(moved); !seen[key] {
+						seen[key] = true
+						next = append(next, moved)
+					}
+				}
+			}
+		}
+		level = next
+	}
+	return -1
+}
+
+func TestRepairUpdateExample(t *testing.T) {
+	input := readExample(t)
+
+	tests := []struct {
+		update   Update
+		expected Update
+		moves    int
+	}{
+		{Update{75, 97, 47, 61, 53}, Update{97, 75, 47, 61, 53}, 1},
+		{Update{61, 13, 29}, Update{61, 29, 13}, 1},
+		{Update{97, 13, 75, 29, 47}, Update{97, 75, 47, 29, 13}, 2},
+		{Update{75, 47, 61, 53, 29}, Update{75, 47, 61, 53, 29}, 0},
+	}
+
+	for _, tt := range tests {
+		repair, err := RepairUpdate(tt.update, input.Rules)
+		if err != nil {
+			t.Fatalf("RepairUpdate(%v) error = %v", tt.update, err)
+		}
+		if fmt.Sprint(repair.Fixed) != fmt.Sprint(tt.expected) {
+			t.Errorf("RepairUpdate(%v).Fixed = %v, expected %v", tt.update, repair.Fixed, tt.expected)
+		}
+		if len(repair.Moves) != tt.moves {
+			t.Errorf("RepairUpdate(%v) took %d moves, expected %d: %v", tt.update, len(repair.Moves), tt.moves, repair.Moves)
+		}
+		applied, err := applyMoves(tt.update, repair.Moves)
+		if err != nil {
+			t.Errorf("RepairUpdate(%v) moves: %v", tt.update, err)
+		} else if fmt.Sprint(applied) != fmt.Sprint(repair.Fixed) {
+			t.Errorf("RepairUpdate(%v) moves give %v, expected %v", tt.update, applied, repair.Fixed)
+		}
+	}
+}
+
+func TestRepairUpdateNeedsChainedRules(t *testing.T) {
+	// No rule relates 1 and 3 directly, but 3|2 and 2|1 put 3 first, so
+	// keeping both 1 and 3 where they are leaves nowhere to put 2
+	rules := []OrderingRule{{3, 2}, {2, 1}}
+	update := Update{1, 3, 2}
+
+	repair, err := RepairUpdate(update, rules)
+	if err != nil {
+		t.Fatalf("RepairUpdate() error = %v", err)
+	}
+	expected := []Move{{Page: 1, From: 0, To: 2}}
+	if fmt.Sprint(repair.Fixed) != "[3 2 1]" || fmt.Sprint(repair.Moves) != fmt.Sprint(expected) {
+		t.Errorf("RepairUpdate(%v) = %v with moves %v, expected [3 2 1] with moves %v", update, repair.Fixed, repair.Moves, expected)
+	}
+}
+
+func TestRepairUpdateCycle(t *testing.T) {
+	rules := []OrderingRule{{1, 2}, {2, 3}, {3, 1}}
+
+	_, err := RepairUpdate(Update{1, 2, 3}, rules)
+	var cycleErr *CycleError
+	if !errors.As(err, &cycleErr) {
+		t.Fatalf("RepairUpdate() error = %v, expected a *CycleError", err)
+	}
+	assertCycleFollowsRules(t, cycleErr.Cycle, rules)
+}
+
+func TestRepairUpdateMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(50))
+
+	for trial := 0; trial < 300; trial++ {
+		var rules []OrderingRule
+		for r := rng.Intn(12); r > 0; r-- {
+			rules = append(rules, OrderingRule{Before: rng.Intn(8), After: rng.Intn(8)})
+		}
+		update := Update(rng.Perm(8)[:1+rng.Intn(6)])
+
+		repair, err := RepairUpdate(update, rules)
+		_, reorderErr := FixUpdateOrder(update, rules)
+		if (err != nil) != (reorderErr != nil) {
+			t.Fatalf("RepairUpdate(%v, %v) error = %v, FixUpdateOrder error = %v", update, rules, err, reorderErr)
+		}
+		if err != nil {
+			continue
+		}
+
+		if !IsValidUpdate(repair.Fixed, rules) {
+			t.Errorf("RepairUpdate(%v, %v).Fixed = %v breaks the rules", update, rules, repair.Fixed)
+		}
+		if expected := bruteMinimumMoves(update, rules); len(repair.Moves) != expected {
+			t.Errorf("RepairUpdate(%v, %v) took %d moves, expected %d", update, rules, len(repair.Moves), expected)
+		}
+		applied, err := applyMoves(update, repair.Moves)
+		if err != nil {
+			t.Fatalf("RepairUpdate(%v, %v) moves: %v", update, rules, err)
+		}
+		if fmt.Sprint(applied) != fmt.Sprint(repair.Fixed) {
+			t.Errorf("RepairUpdate(%v, %v) moves give %v, expected %v", update, rules, applied, repair.Fixed)
+		}
+	}
+}
+
+func TestSolvePart2WithStrategies(t *testing.T) {
+	for _, strategy := range []RepairStrategy{RepairReorder, RepairMinimal} {
+		result, err := SolvePart2With("example-input.txt", strategy)
+		if err != nil {
+			t.Fatalf("SolvePart2With(%s) error = %v", strategy, err)
+		}
+		if result != 123 {
+			t.Errorf("SolvePart2With(%s) = %d, expected 123", strategy, result)
+		}
+	}
+
+	if _, err := ParseRepairStrategy("shuffle"); err == nil {
+		t.Error("ParseRepairStrategy(\"shuffle\") expected an error")
+	}
+}
+
+func TestRepairFile(t *testing.T) {
+	report, err := RepairFile("example-input.txt")
+	if err != nil {
+		t.Fatalf("RepairFile() error = %v", err)
+	}
+	if len(report.Updates) != 3 || report.Moves != 4 {
+		t.Errorf("RepairFile() repaired %d updates with %d moves, expected 3 with 4", len(report.Updates), report.Moves)
+	}
+	if report.ReorderSum != 123 || report.MinimalSum != 123 {
+		t.Errorf("RepairFile() sums = %d and %d, expected 123 for both", report.ReorderSum, report.MinimalSum)
+	}
+}
@@ -0,0 +1,189 @@
+package day05
+
+import "sort"
+
+// denseMaxSpan is the widest range of page numbers a RuleSet keeps as a bit
+// matrix. At 4096 pages the matrix takes 2MB; wider ranges use sorted lists.
+const denseMaxSpan = 1 << 12
+
+// RuleSet indexes ordering rules by page, so an update of k pages can be
+// checked in O(k²) lookups however many rules there are. Build one per input
+// with NewRuleSet and reuse it for every update.
+type RuleSet struct {
+	rules []OrderingRule
+
+	// byBefore maps a page to the indices of the rules it must come first in
+	byBefore map[int][]int
+
+	// When every page lies in [lo, lo+span) with span at most denseMaxSpan,
+	// bit (before-lo)*span + (after-lo) of matrix is set for each rule;
+	// otherwise successors holds the sorted, distinct After pages of each page
+	lo, span   int
+	matrix     []uint64
+	successors map[int][]int
+}
+
+// NewRuleSet indexes rules. It keeps rules as given, so duplicates still
+// appear in Violations as they do in the package-level functions.
+func NewRuleSet(rules []OrderingRule) *RuleSet {
+	rs := &RuleSet{rules: rules, byBefore: make(map[int][]int)}
+	if len(rules) == 0 {
+		return rs
+	}
+
+	lo, hi := rules[0].Before, rules[0].Before
+	for i, rule := range rules {
+		rs.byBefore[rule.Before] = append(rs.byBefore[rule.Before], i)
+		lo, hi = min(lo, rule.Before, rule.After), max(hi, rule.Before, rule.After)
+	}
+
+	// hi-lo as unsigned cannot overflow, however far apart the pages are
+	if gap := uint64(hi) - uint64(lo); gap < denseMaxSpan {
+		rs.lo, rs.span = lo, int(gap)+1
+		rs.matrix = make([]uint64, (rs.span*rs.span+63)/64)
+		for _, rule := range rules {
+			bit := (rule.Before-lo)*rs.span + (rule.After - lo)
+			rs.matrix[bit/64] |= 1 << (bit % 64)
+		}
+		return rs
+	}
+
+	rs.successors = make(map[int][]int, len(rs.byBefore))
+	for before, indices := range rs.byBefore {
+		afters := make([]int, 0, len(indices))
+		for _, i := range indices {
+			afters = append(afters, rules[i].After)
+		}
+		sort.Ints(afters)
+		rs.successors[before] = compactInts(afters)
+	}
+	return rs
+}
+
+// compactInts removes adjacent duplicates from a sorted slice
+func compactInts(values []int) []int {
+	var out []int
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// Rules returns the rules the set was built from
+func (rs *RuleSet) Rules() []OrderingRule {
+	return rs.rules
+}
+
+// Requires reports whether a rule says before must come before after
+func (rs *RuleSet) Requires(before, after int) bool {
+	if rs.matrix != nil {
+		b, a := before-rs.lo, after-rs.lo
+		if b < 0 || b >= rs.span || a < 0 || a >= rs.span {
+			return false
+		}
+		bit := b*rs.span + a
+		return rs.matrix[bit/64]&(1<<(bit%64)) != 0
+	}
+
+	afters := rs.successors[before]
+	i := sort.SearchInts(afters, after)
+	return i < len(afters) && afters[i] == after
+}
+
+// lastPositions maps each page of update to its last position, which is where
+// the rules see a page that appears more than once
+func lastPositions(update Update) map[int]int {
+	pagePos := make(map[int]int, len(update))
+	for i, page := range update {
+		pagePos[page] = i
+	}
+	return pagePos
+}
+
+// IsValid reports whether update breaks none of the rules, comparing each pair
+// of its distinct pages
+func (rs *RuleSet) IsValid(update Update) bool {
+	pagePos := lastPositions(update)
+	for i, page := range update {
+		if pagePos[page] != i {
+			continue
+		}
+		// A rule from a page to itself can never be met
+		if rs.Requires(page, page) {
+			return false
+		}
+		for j := i + 1; j < len(update); j++ {
+			if other := update[j]; pagePos[other] == j && rs.Requires(other, page) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Violations returns every rule update breaks, in rule order, looking only at
+// the rules whose Before page is in the update
+func (rs *RuleSet) Violations(update Update) []Violation {
+	pagePos := lastPositions(update)
+
+	var indices []int
+	for page := range pagePos {
+		indices = append(indices, rs.byBefore[page]...)
+	}
+	sort.Ints(indices)
+
+	var violations []Violation
+	for _, i := range indices {
+		rule := rs.rules[i]
+		beforePos := pagePos[rule.Before]
+		afterPos, afterExists := pagePos[rule.After]
+		if afterExists && beforePos >= afterPos {
+			violations = append(violations, Violation{Rule: rule, BeforePos: beforePos, AfterPos: afterPos})
+		}
+	}
+	return violations
+}
+
+// FixOrder puts the pages of update into an order that satisfies every rule
+// between them, as FixUpdateOrder describes
+func (rs *RuleSet) FixOrder(update Update) (Update, error) {
+	// Nodes are positions in the update, so a page that appears twice is
+	// ordered like any other
+	successors := make([][]int, len(update))
+	inDegree := make([]int, len(update))
+	for from, before := range update {
+		for to, after := range update {
+			if rs.Requires(before, after) {
+				successors[from] = append(successors[from], to)
+				inDegree[to]++
+			}
+		}
+	}
+
+	// Taking the earliest ready position each time is O(k²) for k pages, no
+	// more than finding the edges, and keeps the order stable
+	fixed := make(Update, 0, len(update))
+	placed := make([]bool, len(update))
+	for len(fixed) < len(update) {
+		next := -1
+		for i := range update {
+			if !placed[i] && inDegree[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			return nil, &CycleError{Cycle: findCycle(update, successors, placed)}
+		}
+
+		placed[next] = true
+		fixed = append(fixed, update[next])
+		for _, to := range successors[next] {
+			inDegree[to]--
+		}
+	}
+
+	return fixed, nil
+}
@@ -0,0 +1,123 @@
+package day05
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// naiveViolations checks every rule against the update, as IsValidUpdate once did
+func naiveViolations(update Update, rules []OrderingRule) []Violation {
+	pagePos := make(map[int]int)
+	for i, page := range update {
+		pagePos[page] = i
+	}
+
+	var violations []Violation
+	for _, rule := range rules {
+		beforePos, beforeExists := pagePos[rule.Before]
+		afterPos, afterExists := pagePos[rule.After]
+		if beforeExists && afterExists && beforePos >= afterPos {
+			violations = append(violations, Violation{Rule: rule, BeforePos: beforePos, AfterPos: afterPos})
+		}
+	}
+	return violations
+}
+
+// spread moves pages far apart so a RuleSet cannot use its bit matrix
+func spread(rules []OrderingRule, update Update) ([]OrderingRule, Update) {
+	wide := make([]OrderingRule, len(rules))
+	for i, rule := range rules {
+		wide[i] = OrderingRule{Before: rule.Before * 10000, After: rule.After * 10000}
+	}
+	wideUpdate := make(Update, len(update))
+	for i, page := range update {
+		wideUpdate[i] = page * 10000
+	}
+	return wide, wideUpdate
+}
+
+func TestRuleSetDenseAndSparse(t *testing.T) {
+	rules := []OrderingRule{{1, 2}, {2, 3}, {1, 3}, {2, 3}, {4, 4}}
+
+	dense := NewRuleSet(rules)
+	if dense.matrix == nil {
+		t.Fatal("pages 1-4 should use the bit matrix")
+	}
+	wide, _ := spread(rules, nil)
+	sparse := NewRuleSet(wide)
+	if sparse.matrix != nil {
+		t.Fatal("pages 10000-40000 should use sorted lists")
+	}
+
+	for _, rule := range rules {
+		if !dense.Requires(rule.Before, rule.After) || !sparse.Requires(rule.Before*10000, rule.After*10000) {
+			t.Errorf("Requires(%d, %d) = false for a rule", rule.Before, rule.After)
+		}
+	}
+	for _, pair := range [][2]int{{2, 1}, {3, 1}, {1, 4}, {0, 1}, {5, 5}, {-7, 100}} {
+		if dense.Requires(pair[0], pair[1]) || sparse.Requires(pair[0]*10000, pair[1]*10000) {
+			t.Errorf("Requires(%d, %d) = true without a rule", pair[0], pair[1])
+		}
+	}
+}
+
+func TestRuleSetExtremePages(t *testing.T) {
+	rules := []OrderingRule{{math.MinInt, math.MaxInt}, {-1, 1}}
+	rs := NewRuleSet(rules)
+	if !rs.Requires(math.MinInt, math.MaxInt) || !rs.Requires(-1, 1) || rs.Requires(1, -1) {
+		t.Error("Requires() is wrong for rules spanning the whole int range")
+	}
+	if rs.IsValid(Update{math.MaxInt, math.MinInt}) {
+		t.Error("IsValid() = true for an update breaking MinInt|MaxInt")
+	}
+}
+
+func TestRuleSetMatchesNaive(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+
+	for trial := 0; trial < 200; trial++ {
+		// Few pages and many rules give duplicates, self rules and cycles
+		pages := 2 + rng.Intn(8)
+		var rules []OrderingRule
+		for i := rng.Intn(30); i > 0; i-- {
+			rules = append(rules, OrderingRule{Before: rng.Intn(pages), After: rng.Intn(pages)})
+		}
+		update := make(Update, 1+rng.Intn(pages+2))
+		for i := range update {
+			update[i] = rng.Intn(pages)
+		}
+
+		wideRules, wideUpdate := spread(rules, update)
+		for _, tc := range []struct {
+			name   string
+			rules  []OrderingRule
+			update Update
+		}{{"dense", rules, update}, {"sparse", wideRules, wideUpdate}} {
+			rs := NewRuleSet(tc.rules)
+			expected := naiveViolations(tc.update, tc.rules)
+
+			if valid := rs.IsValid(tc.update); valid != (len(expected) == 0) {
+				t.Fatalf("%s: IsValid(%v) = %v with rules %v, expected %v", tc.name, tc.update, valid, tc.rules, len(expected) == 0)
+			}
+
+			violations := rs.Violations(tc.update)
+			if len(violations) != len(expected) {
+				t.Fatalf("%s: Violations(%v) = %v, expected %v", tc.name, tc.update, violations, expected)
+			}
+			for i := range expected {
+				if violations[i] != expected[i] {
+					t.Fatalf("%s: Violations(%v) = %v, expected %v", tc.name, tc.update, violations, expected)
+				}
+			}
+
+			fixed, err := rs.FixOrder(tc.update)
+			if err != nil {
+				continue
+			}
+			if !samePages(tc.update, fixed) || !rs.IsValid(fixed) {
+				t.Fatalf("%s: FixOrder(%v) = %v with rules %v", tc.name, tc.update, fixed, tc.rules)
+			}
+		}
+	}
+}
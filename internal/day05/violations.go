@@ -0,0 +1,178 @@
+package day05
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Violation is a rule an update breaks: the rule's Before page is at BeforePos,
+// which is at or after its After page at AfterPos
+type Violation struct {
+	Rule      OrderingRule `json:"rule"`
+	BeforePos int          `json:"beforePos"`
+	AfterPos  int          `json:"afterPos"`
+}
+
+// Violations returns every rule update breaks, in rule order. A page that
+// appears more than once is taken at its last position, as IsValidUpdate does.
+func Violations(update Update, rules []OrderingRule) []Violation {
+	return NewRuleSet(rules).Violations(update)
+}
+
+// UpdateCheck explains why one update is out of order and what fixing it takes
+type UpdateCheck struct {
+	Index      int         `json:"index"` // position of the update in the input, from 0
+	Update     Update      `json:"update"`
+	Violations []Violation `json:"violations"`
+	// Moved lists the fewest pages that have to move to put the update in
+	// order, in their original order; the others can stay where they are. It
+	// is empty when the rules between the update's pages form a cycle, which
+	// Cycle then shows.
+	Moved []int `json:"moved"`
+	Cycle []int `json:"cycle,omitempty"`
+	// Moves takes the Moved pages one at a time to where RepairMinimal puts them
+	Moves []Move `json:"moves"`
+}
+
+// CheckUpdate finds the rules update breaks and the pages that have to move
+// to put it right
+func CheckUpdate(index int, update Update, rules []OrderingRule) (UpdateCheck, error) {
+	return NewRuleSet(rules).Check(index, update)
+}
+
+// Check is CheckUpdate against the rule set
+func (rs *RuleSet) Check(index int, update Update) (UpdateCheck, error) {
+	check := UpdateCheck{Index: index, Update: update, Violations: rs.Violations(update), Moved: []int{}, Moves: []Move{}}
+	if len(check.Violations) == 0 {
+		check.Violations = []Violation{}
+		return check, nil
+	}
+
+	_, keep, err := rs.largestKept(update)
+	var cycleErr *CycleError
+	switch {
+	case errors.As(err, &cycleErr):
+		check.Cycle = cycleErr.Cycle
+		return check, nil
+	case err != nil:
+		return UpdateCheck{}, err
+	}
+
+	for i, page := range update {
+		if !keep[i] {
+			check.Moved = append(check.Moved, page)
+		}
+	}
+
+	repair, err := rs.RepairMinimal(update)
+	if err != nil {
+		return UpdateCheck{}, err
+	}
+	check.Moves = repair.Moves
+	return check, nil
+}
+
+// RuleCount is how often the updates break one rule
+type RuleCount struct {
+	Rule  OrderingRule `json:"rule"`
+	Count int          `json:"count"`
+}
+
+// ViolationSummary shows which rules the invalid updates of an input break
+// most often, and each invalid update's check
+type ViolationSummary struct {
+	Updates     int           `json:"updates"`
+	Invalid     int           `json:"invalid"`
+	Violations  int           `json:"violations"`
+	RulesBroken int           `json:"rulesBroken"` // distinct rules broken at least once
+	TopRules    []RuleCount   `json:"topRules"`    // most often broken first
+	Checks      []UpdateCheck `json:"checks"`
+}
+
+// SummariseViolationsFile reads the input in filename and summarises the rules
+// its updates break, listing the topK most often broken
+func SummariseViolationsFile(filename string, topK int) (*ViolationSummary, error) {
+	input, err := readInput(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return SummariseViolations(input, topK)
+}
+
+// SummariseViolations checks every update of input and counts the rules broken
+func SummariseViolations(input PuzzleInput, topK int) (*ViolationSummary, error) {
+	summary := &ViolationSummary{Updates: len(input.Updates), Checks: []UpdateCheck{}}
+
+	ruleSet := NewRuleSet(input.Rules)
+	counts := make(map[OrderingRule]int)
+	for i, update := range input.Updates {
+		check, err := ruleSet.Check(i, update)
+		if err != nil {
+			return nil, fmt.Errorf("update %d: %w", i+1, err)
+		}
+		if len(check.Violations) == 0 {
+			continue
+		}
+
+		summary.Invalid++
+		summary.Violations += len(check.Violations)
+		summary.Checks = append(summary.Checks, check)
+		for _, v := range check.Violations {
+			counts[v.Rule]++
+		}
+	}
+
+	rules := make([]RuleCount, 0, len(counts))
+	for rule, count := range counts {
+		rules = append(rules, RuleCount{Rule: rule, Count: count})
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		a, b := rules[i], rules[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Rule.Before != b.Rule.Before {
+			return a.Rule.Before < b.Rule.Before
+		}
+		return a.Rule.After < b.Rule.After
+	})
+	summary.RulesBroken = len(rules)
+	summary.TopRules = rules[:min(max(topK, 0), len(rules))]
+
+	return summary, nil
+}
+
+// WriteTable writes the totals, the most often broken rules and each invalid
+// update with the pages that have to move and where they go
+func (s *ViolationSummary) WriteTable(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%d updates: %d valid, %d invalid breaking %d rules in total\n",
+		s.Updates, s.Updates-s.Invalid, s.Invalid, s.Violations)
+
+	fmt.Fprintf(&b, "\nMost broken rules (%d of %d)\n", len(s.TopRules), s.RulesBroken)
+	for _, rc := range s.TopRules {
+		fmt.Fprintf(&b, "  %-12s %6d\n", fmt.Sprintf("%d|%d", rc.Rule.Before, rc.Rule.After), rc.Count)
+	}
+
+	fmt.Fprintf(&b, "\nInvalid updates (%d)\n", len(s.Checks))
+	for _, check := range s.Checks {
+		if check.Cycle != nil {
+			fmt.Fprintf(&b, "  update %d %v: %d rules broken, rules form a cycle %v\n",
+				check.Index+1, check.Update, len(check.Violations), check.Cycle)
+			continue
+		}
+		fmt.Fprintf(&b, "  update %d %v: %d rules broken, move %v\n",
+			check.Index+1, check.Update, len(check.Violations), check.Moved)
+		for _, move := range check.Moves {
+			fmt.Fprintf(&b, "    move %d from %d to %d\n", move.Page, move.From, move.To)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
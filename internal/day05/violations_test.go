@@ -0,0 +1,126 @@
+package day05
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func readExample(t *testing.T) PuzzleInput {
+	t.Helper()
+	content, err := os.ReadFile("example-input.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	input, err := ParseInput(string(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return input
+}
+
+func TestViolations(t *testing.T) {
+	input := readExample(t)
+
+	// 61,13,29 breaks 29|13 only
+	violations := Violations(Update{61, 13, 29}, input.Rules)
+	expected := Violation{Rule: OrderingRule{Before: 29, After: 13}, BeforePos: 2, AfterPos: 1}
+	if len(violations) != 1 || violations[0] != expected {
+		t.Errorf("Violations() = %+v, expected [%+v]", violations, expected)
+	}
+
+	for i, update := range input.Updates {
+		if valid, none := IsValidUpdate(update, input.Rules), len(Violations(update, input.Rules)) == 0; valid != none {
+			t.Errorf("update %d: IsValidUpdate() = %v but Violations() found none = %v", i, valid, none)
+		}
+	}
+}
+
+func TestCheckUpdate(t *testing.T) {
+	input := readExample(t)
+
+	// Only page 3 is out of place, though every page shifts once it moves
+	lastRules := []OrderingRule{{Before: 1, After: 3}, {Before: 2, After: 3}, {Before: 4, After: 3}, {Before: 5, After: 3}}
+
+	tests := []struct {
+		update     Update
+		rules      []OrderingRule
+		violations int
+		moved      []int
+	}{
+		{Update{75, 47, 61, 53, 29}, input.Rules, 0, []int{}},
+		{Update{75, 97, 47, 61, 53}, input.Rules, 1, []int{75}},
+		{Update{61, 13, 29}, input.Rules, 1, []int{13}},
+		{Update{97, 13, 75, 29, 47}, input.Rules, 4, []int{13, 29}},
+		{Update{3, 1, 2, 4, 5}, lastRules, 4, []int{3}},
+	}
+
+	for _, tt := range tests {
+		check, err := CheckUpdate(0, tt.update, tt.rules)
+		if err != nil {
+			t.Fatalf("CheckUpdate(%v) error = %v", tt.update, err)
+		}
+		if len(check.Violations) != tt.violations {
+			t.Errorf("CheckUpdate(%v) found %d violations, expected %d", tt.update, len(check.Violations), tt.violations)
+		}
+		if len(check.Moved) != len(tt.moved) {
+			t.Errorf("CheckUpdate(%v).Moved = %v, expected %v", tt.update, check.Moved, tt.moved)
+			continue
+		}
+		for i := range tt.moved {
+			if check.Moved[i] != tt.moved[i] {
+				t.Errorf("CheckUpdate(%v).Moved = %v, expected %v", tt.update, check.Moved, tt.moved)
+				break
+			}
+		}
+
+		// The moves are the ones the repairs view shows, one per moved page
+		repair, err := RepairUpdate(tt.update, tt.rules)
+		if err != nil {
+			t.Fatalf("RepairUpdate(%v) error = %v", tt.update, err)
+		}
+		if len(check.Moves) != len(tt.moved) || fmt.Sprint(check.Moves) != fmt.Sprint(repair.Moves) {
+			t.Errorf("CheckUpdate(%v).Moves = %v, expected %v", tt.update, check.Moves, repair.Moves)
+		}
+	}
+}
+
+func TestCheckUpdateCycle(t *testing.T) {
+	rules := []OrderingRule{{Before: 1, After: 2}, {Before: 2, After: 1}}
+
+	check, err := CheckUpdate(0, Update{1, 2}, rules)
+	if err != nil {
+		t.Fatalf("CheckUpdate() error = %v", err)
+	}
+	if len(check.Violations) != 1 || len(check.Moved) != 0 || len(check.Cycle) != 2 {
+		t.Errorf("CheckUpdate() = %+v, expected one violation and a two-page cycle", check)
+	}
+}
+
+func TestSummariseViolations(t *testing.T) {
+	input := readExample(t)
+
+	summary, err := SummariseViolations(input, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Updates != 6 || summary.Invalid != 3 || summary.Violations != 6 || summary.RulesBroken != 5 {
+		t.Errorf("SummariseViolations() = %+v, expected 3 of 6 updates breaking 5 distinct rules 6 times", summary)
+	}
+	if len(summary.TopRules) != 2 {
+		t.Errorf("SummariseViolations() kept %d rules, expected 2", len(summary.TopRules))
+	}
+	if first := summary.TopRules[0]; first != (RuleCount{Rule: OrderingRule{Before: 29, After: 13}, Count: 2}) {
+		t.Errorf("first rule = %+v, expected 29|13 broken twice", first)
+	}
+
+	var out bytes.Buffer
+	if err := summary.WriteTable(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "update 4 [75 97 47 61 53]: 1 rules broken, move [75]\n    move 75 from 0 to 1\n") {
+		t.Errorf("WriteTable() missing update 4:\n%s", out.String())
+	}
+}
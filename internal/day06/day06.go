@@ -3,10 +3,14 @@ package day06
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
+
+	"advent-of-code-2024/internal/trace"
 )
 
 // Position represents a coordinate on the grid
@@ -24,6 +28,21 @@ const (
 	Left
 )
 
+func (d Direction) String() string {
+	switch d {
+	case Up:
+		return "up"
+	case Right:
+		return "right"
+	case Down:
+		return "down"
+	case Left:
+		return "left"
+	default:
+		return fmt.Sprintf("Direction(%d)", int(d))
+	}
+}
+
 // Guard represents the guard's current state
 type Guard struct {
 	Position  Position
@@ -48,14 +67,22 @@ func parseInput(filename string) (*Grid, error) {
 	}
 	defer file.Close()
 
+	return parseReader(file)
+}
+
+// parseReader reads a rectangular lab map from r, one row per non-empty line
+func parseReader(r io.Reader) (*Grid, error) {
 	var lines []string
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(r)
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 		if line == "" {
 			continue
 		}
+		if len(lines) > 0 && len([]rune(line)) != len([]rune(lines[0])) {
+			return nil, fmt.Errorf("row %d has %d columns, expected %d", len(lines), len([]rune(line)), len([]rune(lines[0])))
+		}
 		lines = append(lines, line)
 	}
 
@@ -151,33 +178,15 @@ func turnRight(direction Direction) Direction {
 	}
 }
 
-func simulatePatrol(grid *Grid, guard *Guard) int {
-	visited := make(map[Position]bool)
-	currentGuard := *guard
-
-	for {
-		// Mark current position as visited
-		visited[currentGuard.Position] = true
-
-		// Get next position in current direction
-		nextPos := getNextPosition(currentGuard.Position, currentGuard.Direction)
-
-		// Check if next position is out of bounds (guard leaves the area)
-		if !isInBounds(grid, nextPos) {
-			break
-		}
-
-		// Check if next position has obstacle
-		if isObstacle(grid, nextPos) {
-			// Turn right and stay at current position
-			currentGuard.Direction = turnRight(currentGuard.Direction)
-		} else {
-			// Move forward to next position
-			currentGuard.Position = nextPos
-		}
+// simulatePatrol returns the number of distinct positions the guard visits
+// before leaving the mapped area
+func simulatePatrol(grid *Grid, guard *Guard) (int, error) {
+	visited, err := getPatrolPath(grid, guard)
+	if err != nil {
+		return 0, err
 	}
 
-	return len(visited)
+	return len(visited), nil
 }
 
 // SolvePart1 solves part 1 of the Day 6 puzzle by simulating the guard's patrol
@@ -193,78 +202,89 @@ func SolvePart1(filename string) (int, error) {
 		return 0, err
 	}
 
-	return simulatePatrol(grid, guard), nil
+	return simulatePatrol(grid, guard)
 }
 
-// getPatrolPath simulates the original patrol and returns all positions visited
-func getPatrolPath(grid *Grid, guard *Guard) map[Position]bool {
-	visited := make(map[Position]bool)
+// walkPatrol moves the guard from its starting state until it leaves the mapped
+// area or comes back to a state it has already been in, which means it is stuck
+// in a loop. visit, when not nil, sees each state in order and whether the guard
+// turns right there instead of stepping forward. walkPatrol reports whether the
+// guard looped and the state it stopped in.
+func walkPatrol(grid *Grid, guard *Guard, visit func(state GuardState, turns bool)) (bool, GuardState) {
+	visitedStates := make(map[GuardState]bool)
 	currentGuard := *guard
 
 	for {
-		// Mark current position as visited
-		visited[currentGuard.Position] = true
+		// Check if we've seen this state before (loop detected)
+		state := GuardState{Position: currentGuard.Position, Direction: currentGuard.Direction}
+		if visitedStates[state] {
+			return true, state
+		}
+		visitedStates[state] = true
 
 		// Get next position in current direction
 		nextPos := getNextPosition(currentGuard.Position, currentGuard.Direction)
 
 		// Check if next position is out of bounds (guard leaves the area)
 		if !isInBounds(grid, nextPos) {
-			break
+			if visit != nil {
+				visit(state, false)
+			}
+			return false, state
 		}
 
-		// Check if next position has obstacle
-		if isObstacle(grid, nextPos) {
-			// Turn right and stay at current position
+		// Turn right and stay at current position if the way is blocked,
+		// otherwise move forward to next position
+		turns := isObstacle(grid, nextPos)
+		if visit != nil {
+			visit(state, turns)
+		}
+		if turns {
 			currentGuard.Direction = turnRight(currentGuard.Direction)
 		} else {
-			// Move forward to next position
 			currentGuard.Position = nextPos
 		}
 	}
+}
 
-	return visited
+// getPatrolPath simulates the original patrol and returns all positions visited.
+// It returns an error if the guard never leaves the mapped area.
+func getPatrolPath(grid *Grid, guard *Guard) (map[Position]bool, error) {
+	visited := make(map[Position]bool)
+
+	loop, last := walkPatrol(grid, guard, func(state GuardState, turns bool) {
+		visited[state.Position] = true
+
+		if turns && trace.Enabled() {
+			obstacle := getNextPosition(state.Position, state.Direction)
+			newDirection := turnRight(state.Direction)
+			trace.Emit("turn", fmt.Sprintf("obstacle at (%d,%d): guard at (%d,%d) turns from %v to %v",
+				obstacle.Row, obstacle.Col, state.Position.Row, state.Position.Col, state.Direction, newDirection),
+				map[string]any{"row": state.Position.Row, "col": state.Position.Col,
+					"from": state.Direction.String(), "to": newDirection.String()})
+		}
+	})
+
+	// A repeated state means the guard is stuck and will never leave
+	if loop {
+		return nil, fmt.Errorf("guard is stuck in a loop at %v", last.Position)
+	}
+
+	if trace.Enabled() {
+		trace.Emit("exit", fmt.Sprintf("guard leaves the map heading %v from (%d,%d) after visiting %d positions",
+			last.Direction, last.Position.Row, last.Position.Col, len(visited)),
+			map[string]any{"row": last.Position.Row, "col": last.Position.Col, "visited": len(visited)})
+	}
+
+	return visited, nil
 }
 
 // simulatePatrolWithLoopDetection simulates the guard's patrol and returns:
 // - true if the guard gets stuck in a loop
 // - false if the guard leaves the mapped area
 func simulatePatrolWithLoopDetection(grid *Grid, guard *Guard) bool {
-	visitedStates := make(map[GuardState]bool)
-	currentGuard := *guard
-	
-	for {
-		// Create current state
-		state := GuardState{
-			Position:  currentGuard.Position,
-			Direction: currentGuard.Direction,
-		}
-		
-		// Check if we've seen this state before (loop detected)
-		if visitedStates[state] {
-			return true
-		}
-		
-		// Mark current state as visited
-		visitedStates[state] = true
-		
-		// Get next position in current direction
-		nextPos := getNextPosition(currentGuard.Position, currentGuard.Direction)
-		
-		// Check if next position is out of bounds (guard leaves the area)
-		if !isInBounds(grid, nextPos) {
-			return false
-		}
-		
-		// Check if next position has obstacle
-		if isObstacle(grid, nextPos) {
-			// Turn right and stay at current position
-			currentGuard.Direction = turnRight(currentGuard.Direction)
-		} else {
-			// Move forward to next position
-			currentGuard.Position = nextPos
-		}
-	}
+	loop, _ := walkPatrol(grid, guard, nil)
+	return loop
 }
 
 // SolvePart2 solves part 2 of the Day 6 puzzle by finding all positions where
@@ -281,8 +301,22 @@ func SolvePart2(filename string) (int, error) {
 		return 0, err
 	}
 
+	obstacles, err := findLoopObstacles(grid, guard)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(obstacles), nil
+}
+
+// findLoopObstacles returns every position, sorted by row then column, where a
+// single new obstacle would trap the guard in a loop
+func findLoopObstacles(grid *Grid, guard *Guard) ([]Position, error) {
 	// Get all positions visited in the original patrol path
-	patrolPath := getPatrolPath(grid, guard)
+	patrolPath, err := getPatrolPath(grid, guard)
+	if err != nil {
+		return nil, err
+	}
 	guardStartPos := guard.Position
 
 	// Collect positions to test (excluding starting position)
@@ -300,7 +334,7 @@ func SolvePart2(filename string) (int, error) {
 	}
 
 	jobs := make(chan Position, len(positions))
-	results := make(chan bool, len(positions))
+	results := make(chan Position, len(positions))
 
 	// Start workers
 	var wg sync.WaitGroup
@@ -321,8 +355,9 @@ func SolvePart2(filename string) (int, error) {
 				workerGrid.Cells[pos.Row][pos.Col] = '#'
 
 				// Test if this creates a loop
-				hasLoop := simulatePatrolWithLoopDetection(workerGrid, guard)
-				results <- hasLoop
+				if simulatePatrolWithLoopDetection(workerGrid, guard) {
+					results <- pos
+				}
 
 				// Restore original cell
 				workerGrid.Cells[pos.Row][pos.Col] = originalCell
@@ -343,12 +378,24 @@ func SolvePart2(filename string) (int, error) {
 	}()
 
 	// Collect results
-	loopPositions := 0
-	for hasLoop := range results {
-		if hasLoop {
-			loopPositions++
+	var obstacles []Position
+	for pos := range results {
+		obstacles = append(obstacles, pos)
+	}
+
+	sort.Slice(obstacles, func(i, j int) bool {
+		if obstacles[i].Row != obstacles[j].Row {
+			return obstacles[i].Row < obstacles[j].Row
+		}
+		return obstacles[i].Col < obstacles[j].Col
+	})
+
+	if trace.Enabled() {
+		for _, pos := range obstacles {
+			trace.Emit("obstacle", fmt.Sprintf("an obstacle at (%d,%d) traps the guard in a loop", pos.Row, pos.Col),
+				map[string]any{"row": pos.Row, "col": pos.Col})
 		}
 	}
 
-	return loopPositions, nil
+	return obstacles, nil
 }
@@ -0,0 +1,64 @@
+package day06
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func FuzzParseInput(f *testing.F) {
+	example, err := os.ReadFile("example-input.txt")
+	if err != nil {
+		f.Fatalf("failed to read example input: %v", err)
+	}
+	f.Add(string(example))
+	f.Add("")
+	f.Add("..^\n.")
+	f.Add(".#.\n#^#\n.#.")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		grid, err := parseReader(strings.NewReader(data))
+		if err != nil {
+			return
+		}
+		if len(grid.Cells) == 0 {
+			t.Fatal("parseReader returned an empty grid without error")
+		}
+		for row := range grid.Cells {
+			if len(grid.Cells[row]) != len(grid.Cells[0]) {
+				t.Fatalf("row %d has %d columns, row 0 has %d", row, len(grid.Cells[row]), len(grid.Cells[0]))
+			}
+		}
+	})
+}
+
+func FuzzSimulatePatrolWithLoopDetection(f *testing.F) {
+	example, err := os.ReadFile("example-input.txt")
+	if err != nil {
+		f.Fatalf("failed to read example input: %v", err)
+	}
+	f.Add(string(example))
+	f.Add(".#.\n#^#\n.#.")
+	f.Add(".#..\n...#\n#^..\n..#.")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		grid, err := parseReader(strings.NewReader(data))
+		if err != nil {
+			return
+		}
+		guard, err := findGuard(grid)
+		if err != nil {
+			return
+		}
+
+		// The loop detector and the part 1 patrol must agree on whether the guard escapes
+		hasLoop := simulatePatrolWithLoopDetection(grid, guard)
+		visited, err := simulatePatrol(grid, guard)
+		if hasLoop != (err != nil) {
+			t.Fatalf("simulatePatrolWithLoopDetection() = %v but simulatePatrol() error = %v", hasLoop, err)
+		}
+		if err == nil && visited == 0 {
+			t.Fatal("simulatePatrol() visited no positions")
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package day06
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"advent-of-code-2024/internal/difftest"
+)
+
+type referenceMap struct {
+	cells              [][]byte
+	guardRow, guardCol int
+	guardDir           int
+}
+
+// referenceDeltas are up, right, down, left, so turning right is +1
+var referenceDeltas = [4][2]int{{-1, 0}, {0, 1}, {1, 0}, {0, -1}}
+
+func referenceParse(input string) (*referenceMap, error) {
+	m := &referenceMap{guardRow: -1}
+	for _, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if len(m.cells) > 0 && len(line) != len(m.cells[0]) {
+			return nil, fmt.Errorf("ragged row %q", line)
+		}
+		if col := strings.IndexAny(line, "^>v<"); col >= 0 && m.guardRow < 0 {
+			m.guardRow, m.guardCol = len(m.cells), col
+			m.guardDir = strings.IndexByte("^>v<", line[col])
+		}
+		m.cells = append(m.cells, []byte(line))
+	}
+	if m.guardRow < 0 {
+		return nil, fmt.Errorf("no guard")
+	}
+	return m, nil
+}
+
+// walk moves the guard one step at a time. The guard can only be in
+// 4*rows*cols distinct states, so taking more steps than that means it loops.
+func (m *referenceMap) walk() (visited map[[2]int]bool, loops bool) {
+	visited = make(map[[2]int]bool)
+	row, col, dir := m.guardRow, m.guardCol, m.guardDir
+	limit := 4 * len(m.cells) * len(m.cells[0])
+
+	for steps := 0; steps <= limit; steps++ {
+		visited[[2]int{row, col}] = true
+		nextRow, nextCol := row+referenceDeltas[dir][0], col+referenceDeltas[dir][1]
+		if nextRow < 0 || nextRow >= len(m.cells) || nextCol < 0 || nextCol >= len(m.cells[0]) {
+			return visited, false
+		}
+		if m.cells[nextRow][nextCol] == '#' {
+			dir = (dir + 1) % 4
+		} else {
+			row, col = nextRow, nextCol
+		}
+	}
+	return visited, true
+}
+
+func referencePart1(input string) (int, error) {
+	m, err := referenceParse(input)
+	if err != nil {
+		return 0, err
+	}
+	visited, loops := m.walk()
+	if loops {
+		return 0, fmt.Errorf("guard loops")
+	}
+	return len(visited), nil
+}
+
+// referencePart2 tries an obstacle on every free cell of the map, one at a time
+func referencePart2(input string) (int, error) {
+	m, err := referenceParse(input)
+	if err != nil {
+		return 0, err
+	}
+	if _, loops := m.walk(); loops {
+		return 0, fmt.Errorf("guard loops")
+	}
+
+	count := 0
+	for row := range m.cells {
+		for col := range m.cells[row] {
+			if m.cells[row][col] == '#' || (row == m.guardRow && col == m.guardCol) {
+				continue
+			}
+			original := m.cells[row][col]
+			m.cells[row][col] = '#'
+			if _, loops := m.walk(); loops {
+				count++
+			}
+			m.cells[row][col] = original
+		}
+	}
+	return count, nil
+}
+
+func TestDifferential(t *testing.T) {
+	difftest.Run(t, 6, []int{2, 8, 20},
+		difftest.Part{Solve: SolvePart1, Reference: referencePart1},
+		difftest.Part{Solve: SolvePart2, Reference: referencePart2})
+}
@@ -1,7 +1,10 @@
 package day06
 
 import (
+	"strings"
 	"testing"
+
+	"advent-of-code-2024/internal/trace"
 )
 
 func TestParseInput(t *testing.T) {
@@ -24,6 +27,13 @@ func TestParseInput(t *testing.T) {
 	}
 }
 
+func TestParseInputRaggedRows(t *testing.T) {
+	_, err := parseReader(strings.NewReader("....\n.^.\n...."))
+	if err == nil {
+		t.Error("Expected error for rows of different lengths, got nil")
+	}
+}
+
 func TestFindGuard(t *testing.T) {
 	grid, err := parseInput("example-input.txt")
 	if err != nil {
@@ -146,7 +156,10 @@ func TestSimulatePatrol(t *testing.T) {
 		t.Fatalf("Expected no error, got %v", err)
 	}
 
-	visitedCount := simulatePatrol(grid, guard)
+	visitedCount, err := simulatePatrol(grid, guard)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
 
 	// From the problem example, the guard should visit 41 distinct positions
 	expectedCount := 41
@@ -155,6 +168,22 @@ func TestSimulatePatrol(t *testing.T) {
 	}
 }
 
+func TestSimulatePatrolLoop(t *testing.T) {
+	grid, err := parseReader(strings.NewReader(".#..\n...#\n#^..\n..#."))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	guard, err := findGuard(grid)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := simulatePatrol(grid, guard); err == nil {
+		t.Error("Expected error for a guard that never leaves the map, got nil")
+	}
+}
+
 func TestSolvePart1(t *testing.T) {
 	result, err := SolvePart1("example-input.txt")
 	if err != nil {
@@ -191,7 +220,7 @@ func TestSimulatePatrolWithLoopDetection(t *testing.T) {
 	if !hasLoop {
 		t.Errorf("Expected loop with obstacle at (6,3), but no loop detected")
 	}
-	
+
 	// Restore original state
 	grid.Cells[6][3] = '.'
 }
@@ -209,3 +238,27 @@ func TestSolvePart2(t *testing.T) {
 	}
 }
 
+func TestTraceEvents(t *testing.T) {
+	var events []trace.Event
+	trace.SetHook(func(e trace.Event) { events = append(events, e) })
+	defer trace.SetHook(nil)
+
+	if _, err := SolvePart1("example-input.txt"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(events) == 0 {
+		t.Fatal("Expected trace events, got none")
+	}
+
+	first := events[0]
+	expected := "obstacle at (0,4): guard at (1,4) turns from up to right"
+	if first.Kind != "turn" || first.Message != expected {
+		t.Errorf("Expected first event %q, got %q (%s)", expected, first.Message, first.Kind)
+	}
+
+	last := events[len(events)-1]
+	if last.Kind != "exit" || last.Fields["visited"] != 41 {
+		t.Errorf("Expected exit event after 41 positions, got %+v", last)
+	}
+}
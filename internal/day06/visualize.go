@@ -0,0 +1,154 @@
+package day06
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// clearScreen moves the cursor home and clears the terminal between frames
+const clearScreen = "\033[H\033[2J"
+
+// VisualizeOptions controls how Visualize renders the guard's patrol
+type VisualizeOptions struct {
+	// Part 2 additionally marks every obstacle position that would trap the guard with 'O'
+	Part int
+	// Delay is the pause between frames; zero renders only the final frame
+	Delay time.Duration
+}
+
+// Visualize draws the guard's patrol over the lab map in the style of the puzzle
+// statement: '|' and '-' for vertical and horizontal movement, '+' where the guard
+// turned or crossed its own path. With a non-zero Delay every step is played back
+// as a separate frame.
+func Visualize(w io.Writer, filename string, opts VisualizeOptions) error {
+	grid, err := parseInput(filename)
+	if err != nil {
+		return err
+	}
+
+	guard, err := findGuard(grid)
+	if err != nil {
+		return err
+	}
+
+	route, err := patrolRoute(grid, guard)
+	if err != nil {
+		return err
+	}
+
+	obstacles := make(map[Position]bool)
+	if opts.Part == 2 {
+		positions, err := findLoopObstacles(grid, guard)
+		if err != nil {
+			return err
+		}
+		for _, pos := range positions {
+			obstacles[pos] = true
+		}
+	}
+
+	marks := newTrail(grid)
+	if opts.Delay > 0 {
+		for i, state := range route {
+			marks.step(route, i)
+			current := state
+			fmt.Fprint(w, clearScreen)
+			fmt.Fprint(w, renderFrame(grid, marks, &current, obstacles))
+			fmt.Fprintf(w, "step %d/%d\n", i+1, len(route))
+			time.Sleep(opts.Delay)
+		}
+		return nil
+	}
+
+	for i := range route {
+		marks.step(route, i)
+	}
+	fmt.Fprint(w, renderFrame(grid, marks, nil, obstacles))
+	if opts.Part == 2 {
+		fmt.Fprintf(w, "%d obstacle positions cause a loop\n", len(obstacles))
+	} else {
+		fmt.Fprintf(w, "%d distinct positions visited\n", marks.visited)
+	}
+	return nil
+}
+
+// patrolRoute returns every state the guard passes through, in order, until it
+// leaves the mapped area
+func patrolRoute(grid *Grid, guard *Guard) ([]GuardState, error) {
+	var route []GuardState
+	loop, last := walkPatrol(grid, guard, func(state GuardState, _ bool) {
+		route = append(route, state)
+	})
+	if loop {
+		return nil, fmt.Errorf("guard is stuck in a loop at %v", last.Position)
+	}
+	return route, nil
+}
+
+// trail records the mark left on each cell by the guard
+type trail struct {
+	marks   [][]rune
+	visited int
+}
+
+func newTrail(grid *Grid) *trail {
+	marks := make([][]rune, len(grid.Cells))
+	for row := range marks {
+		marks[row] = make([]rune, len(grid.Cells[row]))
+	}
+	return &trail{marks: marks}
+}
+
+// step adds the mark for route[i]: a turn on the next step gives '+', otherwise the
+// direction of travel gives '|' or '-', and crossing an existing line gives '+'
+func (t *trail) step(route []GuardState, i int) {
+	state := route[i]
+	mark := '-'
+	if state.Direction == Up || state.Direction == Down {
+		mark = '|'
+	}
+	if i+1 < len(route) && route[i+1].Direction != state.Direction {
+		mark = '+'
+	}
+
+	cell := &t.marks[state.Position.Row][state.Position.Col]
+	switch {
+	case *cell == 0:
+		t.visited++
+		*cell = mark
+	case *cell != mark:
+		*cell = '+'
+	}
+}
+
+var guardGlyphs = map[Direction]rune{Up: '^', Right: '>', Down: 'v', Left: '<'}
+
+// renderFrame draws the map with the trail so far, the guard (when current is not
+// nil) and any loop-causing obstacle positions
+func renderFrame(grid *Grid, marks *trail, current *GuardState, obstacles map[Position]bool) string {
+	var sb strings.Builder
+	for row := range grid.Cells {
+		for col, cell := range grid.Cells[row] {
+			pos := Position{Row: row, Col: col}
+			switch {
+			case current != nil && current.Position == pos:
+				sb.WriteRune(guardGlyphs[current.Direction])
+			case obstacles[pos]:
+				sb.WriteRune('O')
+			case current == nil && strings.ContainsRune("^>v<", cell):
+				// Keep the starting glyph on the final frame, as the puzzle does
+				sb.WriteRune(cell)
+			case marks.marks[row][col] != 0:
+				sb.WriteRune(marks.marks[row][col])
+			case cell == '#':
+				sb.WriteRune('#')
+			default:
+				sb.WriteRune('.')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
@@ -0,0 +1,106 @@
+package day06
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPatrolRoute(t *testing.T) {
+	grid, err := parseInput("example-input.txt")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	guard, err := findGuard(grid)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	route, err := patrolRoute(grid, guard)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if route[0].Position != guard.Position || route[0].Direction != Up {
+		t.Errorf("Expected route to start at %v facing Up, got %v", guard.Position, route[0])
+	}
+
+	distinct := make(map[Position]bool)
+	for _, state := range route {
+		distinct[state.Position] = true
+	}
+	if len(distinct) != 41 {
+		t.Errorf("Expected route to cover 41 positions, got %d", len(distinct))
+	}
+}
+
+func TestVisualizeFinalFrame(t *testing.T) {
+	tests := []struct {
+		part     int
+		expected string
+	}{
+		{
+			part: 1,
+			expected: `....#.....
+....+---+#
+....|...|.
+..#.|...|.
+..+-+-+#|.
+..|.|.|.|.
+.#+-^-+-+.
+.+----++#.
+#+----+|..
+......#|..
+41 distinct positions visited
+`,
+		},
+		{
+			part: 2,
+			expected: `....#.....
+....+---+#
+....|...|.
+..#.|...|.
+..+-+-+#|.
+..|.|.|.|.
+.#+O^-+-+.
+.+----OO#.
+#O-O--+|..
+......#O..
+6 obstacle positions cause a loop
+`,
+		},
+	}
+
+	for _, test := range tests {
+		var out bytes.Buffer
+		if err := Visualize(&out, "example-input.txt", VisualizeOptions{Part: test.part}); err != nil {
+			t.Fatalf("Visualize(part %d) returned error: %v", test.part, err)
+		}
+		if out.String() != test.expected {
+			t.Errorf("Visualize(part %d) =\n%s\nexpected\n%s", test.part, out.String(), test.expected)
+		}
+	}
+}
+
+func TestVisualizePlayback(t *testing.T) {
+	var out bytes.Buffer
+	if err := Visualize(&out, "example-input.txt", VisualizeOptions{Part: 1, Delay: time.Nanosecond}); err != nil {
+		t.Fatalf("Visualize returned error: %v", err)
+	}
+
+	frames := strings.Count(out.String(), clearScreen)
+	if frames == 0 {
+		t.Fatal("Expected playback frames, got none")
+	}
+	if !strings.Contains(out.String(), "step 1/") {
+		t.Error("Expected a step counter on each frame")
+	}
+
+	// The first frame shows the guard at its starting position with no trail yet
+	first := strings.Split(out.String(), clearScreen)[1]
+	if !strings.Contains(first, ".#..^.....") {
+		t.Errorf("Expected guard at start in first frame, got\n%s", first)
+	}
+}
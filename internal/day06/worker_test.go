@@ -21,7 +21,10 @@ func SolvePart2WithWorkers(filename string, numWorkers int) (int, error) {
 	}
 
 	// Get all positions visited in the original patrol path
-	patrolPath := getPatrolPath(grid, guard)
+	patrolPath, err := getPatrolPath(grid, guard)
+	if err != nil {
+		return 0, err
+	}
 	guardStartPos := guard.Position
 
 	// Collect positions to test (excluding starting position)
@@ -2,12 +2,17 @@ package day07
 
 import (
 	"bufio"
-	"math"
+	"fmt"
+	"io"
+	"math/big"
 	"os"
 	"runtime"
 	"strconv"
 	"strings"
 	"sync"
+
+	"advent-of-code-2024/internal/checked"
+	"advent-of-code-2024/internal/trace"
 )
 
 type Equation struct {
@@ -16,34 +21,90 @@ type Equation struct {
 }
 
 
-// Evaluate expression left-to-right with mathematical concatenation
-func evaluateExpression(operands []int, operators []string) int {
+// Evaluate expression left-to-right with mathematical concatenation. ok is false
+// if an intermediate result does not fit in an int.
+func evaluateExpression(operands []int, operators []string) (int, bool) {
 	if len(operands) == 0 {
-		return 0
+		return 0, true
 	}
 	if len(operands) == 1 {
-		return operands[0]
+		return operands[0], true
 	}
 	
 	result := operands[0]
+	ok := true
+	for i, op := range operators {
+		if op == "+" {
+			result, ok = checked.Add(result, operands[i+1])
+		} else if op == "*" {
+			result, ok = checked.Mul(result, operands[i+1])
+		} else if op == "||" {
+			result, ok = concatenateNumbersMath(result, operands[i+1])
+		}
+		if !ok {
+			return 0, false
+		}
+	}
+
+	return result, true
+}
+
+// Evaluate expression left-to-right exactly, for expressions that overflow int
+func evaluateExpressionBig(operands []int, operators []string) *big.Int {
+	if len(operands) == 0 {
+		return new(big.Int)
+	}
+
+	result := big.NewInt(int64(operands[0]))
 	for i, op := range operators {
+		operand := big.NewInt(int64(operands[i+1]))
 		if op == "+" {
-			result += operands[i+1]
+			result.Add(result, operand)
 		} else if op == "*" {
-			result *= operands[i+1]
+			result.Mul(result, operand)
 		} else if op == "||" {
-			result = concatenateNumbersMath(result, operands[i+1])
+			shift := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(countDigits(operands[i+1]))), nil)
+			result.Mul(result, shift).Add(result, operand)
 		}
 	}
 	
 	return result
 }
 
+// Check whether operators makes operands evaluate to testValue. An overflowing
+// expression can still come back into range through "* 0" or a negative operand,
+// so it is settled exactly with math/big unless every operand is positive, in
+// which case every operator only grows the result and it can never match.
+func matchesTestValue(testValue int, operands []int, operators []string, positive bool) bool {
+	value, ok := evaluateExpression(operands, operators)
+	if ok {
+		return value == testValue
+	}
+	if positive {
+		return false
+	}
+
+	exact := evaluateExpressionBig(operands, operators)
+	return exact.IsInt64() && exact.Int64() == int64(testValue)
+}
+
 // Check if equation can be solved with iterator pattern and early termination
 func canSolveEquation(testValue int, operands []int, availableOperators []string) bool {
+	if len(operands) == 0 || len(availableOperators) == 0 && len(operands) > 1 {
+		return false
+	}
 	if len(operands) == 1 {
+		if trace.Enabled() && operands[0] == testValue {
+			trace.Emit("solved", fmt.Sprintf("%d = %d", testValue, operands[0]),
+				map[string]any{"testValue": testValue, "operators": "", "expression": strconv.Itoa(operands[0])})
+		}
 		return operands[0] == testValue
 	}
+
+	positive := true
+	for _, operand := range operands {
+		positive = positive && operand > 0
+	}
 	
 	positions := len(operands) - 1
 	operatorCount := len(availableOperators)
@@ -63,23 +124,51 @@ func canSolveEquation(testValue int, operands []int, availableOperators []string
 		}
 		
 		// Test this combination immediately
-		if evaluateExpression(operands, operators) == testValue {
+		if matchesTestValue(testValue, operands, operators, positive) {
+			if trace.Enabled() {
+				expression := formatExpression(operands, operators)
+				trace.Emit("solved", fmt.Sprintf("%d = %s", testValue, expression),
+					map[string]any{"testValue": testValue, "operators": strings.Join(operators, " "), "expression": expression})
+			}
 			return true // Early termination!
 		}
 	}
 	
+	if trace.Enabled() {
+		trace.Emit("unsolvable", fmt.Sprintf("%d cannot be made from %v with %v", testValue, operands, availableOperators),
+			map[string]any{"testValue": testValue, "operands": operands})
+	}
 	return false
 }
 
+// Render operands and operators as a left-to-right expression, e.g. "81 + 40 * 27"
+func formatExpression(operands []int, operators []string) string {
+	var sb strings.Builder
+	sb.WriteString(strconv.Itoa(operands[0]))
+	for i, op := range operators {
+		sb.WriteString(" " + op + " ")
+		sb.WriteString(strconv.Itoa(operands[i+1]))
+	}
+	return sb.String()
+}
+
 // Parse single equation line
 func parseEquation(line string) (Equation, error) {
 	parts := strings.Split(line, ": ")
+	if len(parts) != 2 {
+		return Equation{}, fmt.Errorf("invalid equation format: %s", line)
+	}
+
 	testValue, err := strconv.Atoi(parts[0])
 	if err != nil {
 		return Equation{}, err
 	}
 	
 	operandStrs := strings.Fields(parts[1])
+	if len(operandStrs) == 0 {
+		return Equation{}, fmt.Errorf("equation has no operands: %s", line)
+	}
+
 	operands := make([]int, len(operandStrs))
 	for i, str := range operandStrs {
 		operands[i], err = strconv.Atoi(str)
@@ -99,8 +188,13 @@ func parseInput(filename string) ([]Equation, error) {
 	}
 	defer file.Close()
 
+	return parseReader(file)
+}
+
+// Parse one equation per non-empty line read from r
+func parseReader(r io.Reader) ([]Equation, error) {
 	var equations []Equation
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(r)
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -125,43 +219,88 @@ func parseInput(filename string) ([]Equation, error) {
 
 // Part 1 solution: + and * operators only (parallel)
 func SolvePart1(filename string) (int, error) {
-	equations, err := parseInput(filename)
+	total, err := solve(filename, part1Operators, checked.Strict)
 	if err != nil {
 		return 0, err
 	}
 	
-	part1Operators := []string{"+", "*"}
-	return solveEquationsParallel(equations, part1Operators), nil
+	return total.Int()
 }
 
-// Mathematical concatenation: a * 10^(digits in b) + b
-// Uses Go's optimized math.Pow10
-func concatenateNumbersMath(a, b int) int {
-	// Count digits in b
-	digits := 1
-	if b != 0 {
-		digits = 0
-		temp := b
-		if temp < 0 {
-			temp = -temp
-		}
-		for temp > 0 {
-			digits++
-			temp /= 10
+// SolvePart1Mode is SolvePart1 with a choice of overflow behaviour for the
+// calibration total. In Promote mode the exact total is returned however large.
+func SolvePart1Mode(filename string, mode checked.Mode) (*big.Int, error) {
+	total, err := solve(filename, part1Operators, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	return total.Big()
+}
+
+var (
+	part1Operators = []string{"+", "*"}
+	part2Operators = []string{"+", "*", "||"}
+)
+
+// Parse the input and add up the test values of solvable equations
+func solve(filename string, availableOperators []string, mode checked.Mode) (*checked.Sum, error) {
+	equations, err := parseInput(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return solveEquationsParallel(equations, availableOperators, mode)
+}
+
+// Mathematical concatenation: a * 10^(digits in b) + b. ok is false if the
+// result does not fit in an int.
+func concatenateNumbersMath(a, b int) (int, bool) {
+	shift := 1
+	for range countDigits(b) {
+		var ok bool
+		if shift, ok = checked.Mul(shift, 10); !ok {
+			// Only a 19-digit b gets here, and then only a == 0 fits
+			return b, a == 0
 		}
 	}
-	return a*int(math.Pow10(digits)) + b
+
+	shifted, ok := checked.Mul(a, shift)
+	if !ok {
+		return 0, false
+	}
+	return checked.Add(shifted, b)
+}
+
+// Count the decimal digits in n, ignoring any sign
+func countDigits(n int) int {
+	digits := 1
+	for n >= 10 || n <= -10 {
+		digits++
+		n /= 10
+	}
+	return digits
 }
 
 // Part 2 solution: +, *, and || operators (parallel)
 func SolvePart2(filename string) (int, error) {
-	equations, err := parseInput(filename)
+	total, err := solve(filename, part2Operators, checked.Strict)
 	if err != nil {
 		return 0, err
 	}
 	
-	part2Operators := []string{"+", "*", "||"}
-	return solveEquationsParallel(equations, part2Operators), nil
+	return total.Int()
+}
+
+// SolvePart2Mode is SolvePart2 with a choice of overflow behaviour for the
+// calibration total. In Promote mode the exact total is returned however large.
+func SolvePart2Mode(filename string, mode checked.Mode) (*big.Int, error) {
+	total, err := solve(filename, part2Operators, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	return total.Big()
 }
 
 // Worker pool result for parallel processing
@@ -171,12 +310,12 @@ type EquationResult struct {
 }
 
 // Process equations in parallel using worker pool
-func solveEquationsParallel(equations []Equation, availableOperators []string) int {
-	return solveEquationsParallelWithWorkers(equations, availableOperators, runtime.NumCPU())
+func solveEquationsParallel(equations []Equation, availableOperators []string, mode checked.Mode) (*checked.Sum, error) {
+	return solveEquationsParallelWithWorkers(equations, availableOperators, runtime.NumCPU(), mode)
 }
 
 // Process equations in parallel with custom worker count
-func solveEquationsParallelWithWorkers(equations []Equation, availableOperators []string, numWorkers int) int {
+func solveEquationsParallelWithWorkers(equations []Equation, availableOperators []string, numWorkers int, mode checked.Mode) (*checked.Sum, error) {
 	equationChan := make(chan Equation, len(equations))
 	resultChan := make(chan EquationResult, len(equations))
 	
@@ -210,14 +349,15 @@ func solveEquationsParallelWithWorkers(equations []Equation, availableOperators
 		close(resultChan)
 	}()
 	
-	totalCalibrationResult := 0
+	totalCalibrationResult := checked.NewSum(mode)
 	for result := range resultChan {
 		if result.Solvable {
-			totalCalibrationResult += result.TestValue
+			totalCalibrationResult.Add(result.TestValue)
 		}
 	}
 	
-	return totalCalibrationResult
+	if err := totalCalibrationResult.Err(); err != nil {
+		return nil, fmt.Errorf("total calibration result: %w", err)
+	}
+	return totalCalibrationResult, nil
 }
-
-
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"runtime"
 	"testing"
+
+	"advent-of-code-2024/internal/checked"
 )
 
 // Benchmark Part 1 solution
@@ -29,7 +31,7 @@ func BenchmarkSolvePart2(b *testing.B) {
 // Benchmark concatenation function
 func BenchmarkConcatenateNumbers_Math(b *testing.B) {
 	for i := 0; i < b.N; i++ {
-		_ = concatenateNumbersMath(12345, 6789)
+		_, _ = concatenateNumbersMath(12345, 6789)
 	}
 }
 
@@ -47,7 +49,7 @@ func BenchmarkSolvePart1WorkerCounts(b *testing.B) {
 		numWorkers := numCPU * multiple
 		b.Run(fmt.Sprintf("%dx_CPU_%d_workers", multiple, numWorkers), func(b *testing.B) {
 			for i := 0; i < b.N; i++ {
-				_ = solveEquationsParallelWithWorkers(equations, part1Operators, numWorkers)
+				_, _ = solveEquationsParallelWithWorkers(equations, part1Operators, numWorkers, checked.Strict)
 			}
 		})
 	}
@@ -66,7 +68,7 @@ func BenchmarkSolvePart2WorkerCounts(b *testing.B) {
 		numWorkers := numCPU * multiple
 		b.Run(fmt.Sprintf("%dx_CPU_%d_workers", multiple, numWorkers), func(b *testing.B) {
 			for i := 0; i < b.N; i++ {
-				_ = solveEquationsParallelWithWorkers(equations, part2Operators, numWorkers)
+				_, _ = solveEquationsParallelWithWorkers(equations, part2Operators, numWorkers, checked.Strict)
 			}
 		})
 	}
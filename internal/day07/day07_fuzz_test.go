@@ -0,0 +1,64 @@
+package day07
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// maxFuzzOperands keeps the 3^(n-1) operator search tractable while fuzzing
+const maxFuzzOperands = 10
+
+func FuzzParseInput(f *testing.F) {
+	example, err := os.ReadFile("example-input.txt")
+	if err != nil {
+		f.Fatalf("failed to read example input: %v", err)
+	}
+	f.Add(string(example))
+	f.Add("")
+	f.Add("190 10 19")
+	f.Add("190: ")
+	f.Add("1: 2: 3")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		equations, err := parseReader(strings.NewReader(data))
+		if err != nil {
+			return
+		}
+		for i, equation := range equations {
+			if len(equation.Operands) == 0 {
+				t.Fatalf("equation %d has no operands", i)
+			}
+		}
+	})
+}
+
+func FuzzCanSolveEquation(f *testing.F) {
+	example, err := os.ReadFile("example-input.txt")
+	if err != nil {
+		f.Fatalf("failed to read example input: %v", err)
+	}
+	f.Add(string(example))
+	f.Add("0: 0 0 0")
+	f.Add("-5: -2 3")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		equations, err := parseReader(strings.NewReader(data))
+		if err != nil {
+			return
+		}
+
+		for _, equation := range equations {
+			if len(equation.Operands) > maxFuzzOperands {
+				continue
+			}
+
+			// Adding || can only make more equations solvable
+			part1 := canSolveEquation(equation.TestValue, equation.Operands, []string{"+", "*"})
+			part2 := canSolveEquation(equation.TestValue, equation.Operands, []string{"+", "*", "||"})
+			if part1 && !part2 {
+				t.Fatalf("equation %v solvable with +,* but not with +,*,||", equation)
+			}
+		}
+	})
+}
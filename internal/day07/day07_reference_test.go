@@ -0,0 +1,73 @@
+package day07
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"advent-of-code-2024/internal/difftest"
+)
+
+// referenceSolvable tries every operator at every position, concatenating
+// through strings rather than arithmetic
+func referenceSolvable(target, acc int, rest []int, concat bool) bool {
+	if len(rest) == 0 {
+		return acc == target
+	}
+	if referenceSolvable(target, acc+rest[0], rest[1:], concat) ||
+		referenceSolvable(target, acc*rest[0], rest[1:], concat) {
+		return true
+	}
+	if concat {
+		joined, err := strconv.Atoi(strconv.Itoa(acc) + strconv.Itoa(rest[0]))
+		return err == nil && referenceSolvable(target, joined, rest[1:], concat)
+	}
+	return false
+}
+
+func referenceSolve(input string, concat bool) (int, error) {
+	total := 0
+	for _, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		target, operandList, ok := strings.Cut(line, ": ")
+		if !ok || strings.Contains(operandList, ": ") {
+			return 0, fmt.Errorf("invalid line %q", line)
+		}
+		value, err := strconv.Atoi(target)
+		if err != nil {
+			return 0, err
+		}
+		fields := strings.Fields(operandList)
+		if len(fields) == 0 {
+			return 0, fmt.Errorf("no operands in %q", line)
+		}
+		operands := make([]int, len(fields))
+		for i, field := range fields {
+			if operands[i], err = strconv.Atoi(field); err != nil {
+				return 0, err
+			}
+		}
+		if referenceSolvable(value, operands[0], operands[1:], concat) {
+			total += value
+		}
+	}
+	return total, nil
+}
+
+func referencePart1(input string) (int, error) {
+	return referenceSolve(input, false)
+}
+
+func referencePart2(input string) (int, error) {
+	return referenceSolve(input, true)
+}
+
+func TestDifferential(t *testing.T) {
+	difftest.Run(t, 7, []int{1, 10, 30},
+		difftest.Part{Solve: SolvePart1, Reference: referencePart1},
+		difftest.Part{Solve: SolvePart2, Reference: referencePart2})
+}
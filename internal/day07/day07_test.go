@@ -1,7 +1,15 @@
 package day07
 
 import (
+	"errors"
+	"math"
+	"math/big"
+	"os"
+	"sync"
 	"testing"
+
+	"advent-of-code-2024/internal/checked"
+	"advent-of-code-2024/internal/trace"
 )
 
 
@@ -36,8 +44,8 @@ func TestEvaluateExpression(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run("", func(t *testing.T) {
-			result := evaluateExpression(tt.operands, tt.operators)
-			if result != tt.expected {
+			result, ok := evaluateExpression(tt.operands, tt.operators)
+			if !ok || result != tt.expected {
 				t.Errorf("evaluateExpression(%v, %v) = %d, want %d", tt.operands, tt.operators, result, tt.expected)
 			}
 		})
@@ -96,8 +104,8 @@ func TestConcatenateNumbers(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run("", func(t *testing.T) {
-			result := concatenateNumbersMath(tt.a, tt.b)
-			if result != tt.expected {
+			result, ok := concatenateNumbersMath(tt.a, tt.b)
+			if !ok || result != tt.expected {
 				t.Errorf("concatenateNumbersMath(%d, %d) = %d, want %d", tt.a, tt.b, result, tt.expected)
 			}
 		})
@@ -130,8 +138,8 @@ func TestEvaluateExpressionWithConcatenation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run("", func(t *testing.T) {
-			result := evaluateExpression(tt.operands, tt.operators)
-			if result != tt.expected {
+			result, ok := evaluateExpression(tt.operands, tt.operators)
+			if !ok || result != tt.expected {
 				t.Errorf("evaluateExpression(%v, %v) = %d, want %d", tt.operands, tt.operators, result, tt.expected)
 			}
 		})
@@ -189,7 +197,10 @@ func TestMathematicalConcatenation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run("", func(t *testing.T) {
-			mathResult := concatenateNumbersMath(tt.a, tt.b)
+			mathResult, ok := concatenateNumbersMath(tt.a, tt.b)
+			if !ok {
+				t.Fatalf("concatenateNumbersMath(%d, %d) reported overflow", tt.a, tt.b)
+			}
 			
 			if mathResult != tt.expected {
 				t.Errorf("concatenateNumbersMath(%d, %d) = %d, want %d", tt.a, tt.b, mathResult, tt.expected)
@@ -198,4 +209,131 @@ func TestMathematicalConcatenation(t *testing.T) {
 	}
 }
 
+// Test rejecting malformed equation lines
+func TestParseEquationInvalid(t *testing.T) {
+	tests := []string{
+		"190 10 19",
+		"190: ",
+		"1: 2: 3",
+		"abc: 1 2",
+		"5: 1 x",
+	}
+
+	for _, line := range tests {
+		t.Run(line, func(t *testing.T) {
+			if _, err := parseEquation(line); err == nil {
+				t.Errorf("parseEquation(%q) should return error", line)
+			}
+		})
+	}
+}
+
+// Test that solved equations report the winning operators
+func TestTraceEvents(t *testing.T) {
+	var mu sync.Mutex
+	solved := make(map[int]string)
+	unsolvable := 0
+	trace.SetHook(func(e trace.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch e.Kind {
+		case "solved":
+			solved[e.Fields["testValue"].(int)] = e.Fields["expression"].(string)
+		case "unsolvable":
+			unsolvable++
+		}
+	})
+	defer trace.SetHook(nil)
+
+	if _, err := SolvePart2("example-input.txt"); err != nil {
+		t.Fatalf("SolvePart2 returned error: %v", err)
+	}
+
+	expected := map[int]string{
+		190:  "10 * 19",
+		156:  "15 || 6",
+		7290: "6 * 8 || 6 * 15",
+	}
+	for testValue, expression := range expected {
+		if solved[testValue] != expression {
+			t.Errorf("solved[%d] = %q, want %q", testValue, solved[testValue], expression)
+		}
+	}
+	if len(solved) != 6 || unsolvable != 3 {
+		t.Errorf("Expected 6 solved and 3 unsolvable events, got %d and %d", len(solved), unsolvable)
+	}
+}
+
+func TestConcatenateNumbersMathOverflow(t *testing.T) {
+	tests := []struct {
+		a, b     int
+		expected int
+		ok       bool
+	}{
+		{922337203685477580, 7, math.MaxInt64, true},
+		{922337203685477580, 8, 0, false},
+		{0, 1234567890123456789, 1234567890123456789, true},
+		{1, 1234567890123456789, 0, false},
+		{math.MaxInt64, 1, 0, false},
+	}
+
+	for _, tt := range tests {
+		result, ok := concatenateNumbersMath(tt.a, tt.b)
+		if ok != tt.ok || (ok && result != tt.expected) {
+			t.Errorf("concatenateNumbersMath(%d, %d) = %d, %t, want %d, %t", tt.a, tt.b, result, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestCanSolveEquationOverflow(t *testing.T) {
+	tests := []struct {
+		name      string
+		testValue int
+		operands  []int
+		operators []string
+		expected  bool
+	}{
+		// 2^32 * 2^32 wraps to 0 in int64 arithmetic
+		{"wrapped product", 0, []int{4294967296, 4294967296}, []string{"+", "*"}, false},
+		{"wrapped concatenation", 0, []int{1844674407370955161, 6}, []string{"||"}, false},
+		// MaxInt64 + 1 overflows, then adding -MaxInt64 comes back to 1
+		{"back into range", 1, []int{math.MaxInt64, 1, -math.MaxInt64}, []string{"+"}, true},
+		// 2^62 || 2 and 2^62 * 2 both overflow, but "* 0 || 20" makes 20 either way
+		{"back into range with concatenation", 20, []int{4611686018427387904, 2, 0, 20}, []string{"||", "*"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := canSolveEquation(tt.testValue, tt.operands, tt.operators)
+			if result != tt.expected {
+				t.Errorf("canSolveEquation(%d, %v, %v) = %v, want %v", tt.testValue, tt.operands, tt.operators, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTotalOverflow(t *testing.T) {
+	input := "9223372036854775807: 9223372036854775807\n9223372036854775807: 9223372036854775806 1\n"
+	path := t.TempDir() + "/input.txt"
+	if err := os.WriteFile(path, []byte(input), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := SolvePart1(path); !errors.Is(err, checked.ErrOverflow) {
+		t.Errorf("SolvePart1() error = %v, expected ErrOverflow", err)
+	}
+	if _, err := SolvePart2(path); !errors.Is(err, checked.ErrOverflow) {
+		t.Errorf("SolvePart2() error = %v, expected ErrOverflow", err)
+	}
 
+	expected := new(big.Int).Mul(big.NewInt(math.MaxInt64), big.NewInt(2))
+	for part, solve := range map[int]func(string, checked.Mode) (*big.Int, error){1: SolvePart1Mode, 2: SolvePart2Mode} {
+		result, err := solve(path, checked.Promote)
+		if err != nil {
+			t.Fatalf("SolvePart%dMode() error = %v", part, err)
+		}
+		if result.Cmp(expected) != 0 {
+			t.Errorf("SolvePart%dMode() = %s, expected %s", part, result, expected)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Package difftest compares optimised puzzle solvers against slow reference
+// implementations on generated inputs.
+//
+// Reference implementations live in each day's test files. When the two disagree,
+// the failing input is shrunk to a minimal one that still disagrees before the test
+// fails, so the report points at the smallest reproducible case.
+package difftest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"advent-of-code-2024/internal/gen"
+)
+
+// Solver computes a puzzle answer from the full input text
+type Solver func(input string) (int, error)
+
+// FileSolver adapts a SolvePartN function, which reads its input from a file,
+// to a Solver by writing each input to a temporary file first
+func FileSolver(t testing.TB, solve func(filename string) (int, error)) Solver {
+	filename := filepath.Join(t.TempDir(), "input.txt")
+
+	return func(input string) (int, error) {
+		if err := os.WriteFile(filename, []byte(input), 0o644); err != nil {
+			return 0, err
+		}
+		return solve(filename)
+	}
+}
+
+// seedsPerSize is how many generated inputs Run checks at each size
+const seedsPerSize = 10
+
+// Part pairs one part's solver with its reference implementation
+type Part struct {
+	Solve     func(filename string) (int, error)
+	Reference Solver
+}
+
+// Run checks each part of day against its reference on generated inputs of
+// every size, one subtest per part named Part1, Part2 and so on
+func Run(t *testing.T, day int, sizes []int, parts ...Part) {
+	t.Helper()
+
+	for i, part := range parts {
+		t.Run(fmt.Sprintf("Part%d", i+1), func(t *testing.T) {
+			Check(t, day, sizes, seedsPerSize, FileSolver(t, part.Solve), part.Reference)
+		})
+	}
+}
+
+// Check runs optimised and reference on generated inputs for day, one per size and
+// seed in [1, seeds]. The first disagreement is shrunk and reported with t.Fatalf.
+func Check(t *testing.T, day int, sizes []int, seeds int, optimised, reference Solver) {
+	t.Helper()
+
+	for _, size := range sizes {
+		for seed := int64(1); seed <= int64(seeds); seed++ {
+			input, err := gen.String(day, size, seed)
+			if err != nil {
+				t.Fatalf("gen.String(%d, %d, %d) failed: %v", day, size, seed, err)
+			}
+
+			if !disagree(optimised, reference, input) {
+				continue
+			}
+
+			minimal := Shrink(input, func(candidate string) bool {
+				return disagree(optimised, reference, candidate)
+			})
+			got, gotErr := optimised(minimal)
+			want, wantErr := reference(minimal)
+			t.Fatalf("day %d size %d seed %d: optimised = (%d, %v), reference = (%d, %v) on minimal input:\n%s",
+				day, size, seed, got, gotErr, want, wantErr, minimal)
+		}
+	}
+}
+
+// disagree reports whether the two solvers give different answers for input.
+// Two errors count as agreement, since both rejected the input.
+func disagree(optimised, reference Solver, input string) bool {
+	got, gotErr := optimised(input)
+	want, wantErr := reference(input)
+
+	if gotErr != nil || wantErr != nil {
+		return (gotErr == nil) != (wantErr == nil)
+	}
+	return got != want
+}
+
+// Shrink returns a smaller input for which fails still returns true. It first
+// removes whole lines, then bytes within each remaining line, keeping every
+// removal that preserves the failure.
+func Shrink(input string, fails func(string) bool) string {
+	lines := strings.Split(strings.TrimRight(input, "\n"), "\n")
+	lines = shrinkSlice(lines, func(candidate []string) bool {
+		return fails(joinLines(candidate))
+	})
+
+	for i := range lines {
+		shrunk := shrinkSlice([]byte(lines[i]), func(candidate []byte) bool {
+			original := lines[i]
+			lines[i] = string(candidate)
+			defer func() { lines[i] = original }()
+			return fails(joinLines(lines))
+		})
+		lines[i] = string(shrunk)
+	}
+
+	return joinLines(lines)
+}
+
+// shrinkSlice removes chunks of items, halving the chunk size down to single
+// items, while fails still holds for what remains
+func shrinkSlice[T any](items []T, fails func([]T) bool) []T {
+	for chunk := len(items); chunk >= 1; chunk /= 2 {
+		for start := 0; start < len(items); {
+			end := min(start+chunk, len(items))
+
+			candidate := make([]T, 0, len(items)-(end-start))
+			candidate = append(candidate, items[:start]...)
+			candidate = append(candidate, items[end:]...)
+
+			if fails(candidate) {
+				items = candidate
+			} else {
+				start = end
+			}
+		}
+	}
+	return items
+}
+
+func joinLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,95 @@
+package difftest
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestShrinkLines(t *testing.T) {
+	input := "one\ntwo\nbad\nthree\nfour\n"
+
+	result := Shrink(input, func(candidate string) bool {
+		return strings.Contains(candidate, "bad")
+	})
+
+	if result != "bad\n" {
+		t.Errorf("Shrink() = %q, expected %q", result, "bad\n")
+	}
+}
+
+func TestShrinkBytes(t *testing.T) {
+	input := "xxmul(2,4)yy\nzz\n"
+
+	result := Shrink(input, func(candidate string) bool {
+		return strings.Contains(candidate, "(2,")
+	})
+
+	if result != "(2,\n" {
+		t.Errorf("Shrink() = %q, expected %q", result, "(2,\n")
+	}
+}
+
+func TestShrinkKeepsFailure(t *testing.T) {
+	// Fails whenever the sum of all numbers is at least 10
+	fails := func(candidate string) bool {
+		sum := 0
+		for _, field := range strings.Fields(candidate) {
+			n, _ := strconv.Atoi(field)
+			sum += n
+		}
+		return sum >= 10
+	}
+
+	input := "1 2 3\n4 5 6\n7 8 9\n"
+	result := Shrink(input, fails)
+
+	if !fails(result) {
+		t.Errorf("Shrink() = %q no longer fails", result)
+	}
+	if len(result) >= len(input) {
+		t.Errorf("Shrink() = %q is not smaller than the input", result)
+	}
+}
+
+func TestDisagree(t *testing.T) {
+	constant := func(n int) Solver {
+		return func(string) (int, error) { return n, nil }
+	}
+	failing := func(string) (int, error) { return 0, errors.New("bad input") }
+
+	tests := []struct {
+		name                 string
+		optimised, reference Solver
+		expected             bool
+	}{
+		{"Same answer", constant(3), constant(3), false},
+		{"Different answers", constant(3), constant(4), true},
+		{"Both error", failing, failing, false},
+		{"Only optimised errors", failing, constant(0), true},
+		{"Only reference errors", constant(0), failing, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := disagree(tt.optimised, tt.reference, "input"); result != tt.expected {
+				t.Errorf("disagree() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRunReadsEachPartsInput(t *testing.T) {
+	lines := func(input string) (int, error) { return strings.Count(input, "\n"), nil }
+	readLines := func(filename string) (int, error) {
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return 0, err
+		}
+		return lines(string(content))
+	}
+
+	Run(t, 1, []int{1, 5}, Part{Solve: readLines, Reference: lines}, Part{Solve: readLines, Reference: lines})
+}
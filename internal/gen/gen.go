@@ -0,0 +1,357 @@
+// Package gen generates random puzzle inputs for stress and scale testing.
+//
+// Every implemented day has a generator that writes an input in the same format as
+// the real puzzle-input.txt. The meaning of size depends on the day (number of lines,
+// grid side length, number of updates, ...), and output is fully determined by the
+// seed so that a failing input can always be regenerated.
+package gen
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Generator writes a random puzzle input of the given size using rng
+type Generator func(w *bufio.Writer, rng *rand.Rand, size int) error
+
+var generators = map[int]Generator{
+	1: locationLists,
+	2: reports,
+	3: corruptedMemory,
+	4: wordSearch,
+	5: printQueue,
+	6: labMap,
+	7: calibrations,
+}
+
+// Days returns the days that have a generator, in ascending order
+func Days() []int {
+	days := make([]int, 0, len(generators))
+	for day := range generators {
+		days = append(days, day)
+	}
+	sort.Ints(days)
+	return days
+}
+
+// Generate writes a random input for day to w. The same day, size and seed
+// always produce the same output.
+func Generate(w io.Writer, day, size int, seed int64) error {
+	generator, ok := generators[day]
+	if !ok {
+		return fmt.Errorf("no generator for day %d", day)
+	}
+	if size < 1 {
+		return fmt.Errorf("size must be at least 1, got %d", size)
+	}
+
+	bw := bufio.NewWriter(w)
+	if err := generator(bw, rand.New(rand.NewSource(seed)), size); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// String is a convenience wrapper around Generate for tests and benchmarks
+func String(day, size int, seed int64) (string, error) {
+	var sb strings.Builder
+	if err := Generate(&sb, day, size, seed); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// locationLists writes size lines of two location IDs. Roughly half of the right column
+// is drawn from the left column so that part 2 has matches to count.
+func locationLists(w *bufio.Writer, rng *rand.Rand, size int) error {
+	left := make([]int, size)
+	for i := range left {
+		left[i] = 10000 + rng.Intn(90000)
+	}
+
+	for i := 0; i < size; i++ {
+		right := 10000 + rng.Intn(90000)
+		if rng.Intn(2) == 0 {
+			right = left[rng.Intn(size)]
+		}
+		fmt.Fprintf(w, "%d   %d\n", left[i], right)
+	}
+	return nil
+}
+
+// reports writes size reports of 5-8 levels. A third are safe, a third have one bad
+// level that the dampener can remove, and the rest are random.
+func reports(w *bufio.Writer, rng *rand.Rand, size int) error {
+	for i := 0; i < size; i++ {
+		length := 5 + rng.Intn(4)
+		levels := make([]int, length)
+
+		switch i % 3 {
+		case 0, 1:
+			direction := 1
+			if rng.Intn(2) == 0 {
+				direction = -1
+			}
+			levels[0] = 10 + rng.Intn(80)
+			for j := 1; j < length; j++ {
+				levels[j] = levels[j-1] + direction*(1+rng.Intn(3))
+			}
+			if i%3 == 1 {
+				levels[rng.Intn(length)] += 4 + rng.Intn(5)
+			}
+		default:
+			for j := range levels {
+				levels[j] = 1 + rng.Intn(99)
+			}
+		}
+
+		writeInts(w, levels, " ")
+		w.WriteByte('\n')
+	}
+	return nil
+}
+
+// memoryNoise are fragments that look like instructions but must be ignored
+var memoryNoise = []string{
+	"mul[3,7]", "mul(4*", "mul ( 2 , 4 )", "mul(6,9!", "?(12,34)", "do_not_", "don't",
+	"do(", "select()", "from()", "what()", "how()", "where()", "who()", "when()",
+	"%", "&", "!", "@", "^", "#", "$", "+", "-", "<", ">", "'", "[", "]", "{", "}", "~", "*", ")", "(",
+}
+
+// corruptedMemory writes size tokens of corrupted memory, split over several lines like the
+// real input. Tokens are valid mul(X,Y), do(), don't() or noise.
+func corruptedMemory(w *bufio.Writer, rng *rand.Rand, size int) error {
+	for i := 0; i < size; i++ {
+		switch roll := rng.Intn(20); {
+		case roll < 8:
+			fmt.Fprintf(w, "mul(%d,%d)", 1+rng.Intn(999), 1+rng.Intn(999))
+		case roll == 8:
+			w.WriteString("do()")
+		case roll == 9:
+			w.WriteString("don't()")
+		default:
+			w.WriteString(memoryNoise[rng.Intn(len(memoryNoise))])
+		}
+
+		if (i+1)%500 == 0 {
+			w.WriteByte('\n')
+		}
+	}
+	w.WriteByte('\n')
+	return nil
+}
+
+// wordDirections are the eight directions a word can be planted in
+var wordDirections = [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}, {0, -1}, {-1, 0}, {-1, -1}, {-1, 1}}
+
+// wordSearch writes a size x size grid of X, M, A and S with XMAS words and X-MAS
+// crosses planted at random positions.
+func wordSearch(w *bufio.Writer, rng *rand.Rand, size int) error {
+	const letters = "XMAS"
+
+	grid := make([][]byte, size)
+	for row := range grid {
+		grid[row] = make([]byte, size)
+		for col := range grid[row] {
+			grid[row][col] = letters[rng.Intn(len(letters))]
+		}
+	}
+
+	plants := size * size / 20
+	for i := 0; i < plants; i++ {
+		row, col := rng.Intn(size), rng.Intn(size)
+		if i%2 == 0 {
+			dir := wordDirections[rng.Intn(len(wordDirections))]
+			endRow, endCol := row+3*dir[0], col+3*dir[1]
+			if endRow < 0 || endRow >= size || endCol < 0 || endCol >= size {
+				continue
+			}
+			for k := 0; k < len(letters); k++ {
+				grid[row+k*dir[0]][col+k*dir[1]] = letters[k]
+			}
+			continue
+		}
+
+		if row < 1 || row >= size-1 || col < 1 || col >= size-1 {
+			continue
+		}
+		first, second := "MS", "MS"
+		if rng.Intn(2) == 0 {
+			first = "SM"
+		}
+		if rng.Intn(2) == 0 {
+			second = "SM"
+		}
+		grid[row][col] = 'A'
+		grid[row-1][col-1], grid[row+1][col+1] = first[0], first[1]
+		grid[row-1][col+1], grid[row+1][col-1] = second[0], second[1]
+	}
+
+	for _, row := range grid {
+		w.Write(row)
+		w.WriteByte('\n')
+	}
+	return nil
+}
+
+// printQueue writes rules for every pair of size distinct pages, derived from a hidden
+// total order so the rules never contain a cycle, followed by 4*size updates.
+// Half of the updates are correctly ordered and half are shuffled.
+func printQueue(w *bufio.Writer, rng *rand.Rand, size int) error {
+	if size < 2 {
+		return fmt.Errorf("day 5 needs at least 2 pages, got %d", size)
+	}
+
+	pages := rng.Perm(size * 2)[:size]
+	for i := range pages {
+		pages[i] += 10
+	}
+
+	// pages is already in a random order, so it serves as the hidden total order
+	for i := 0; i < size; i++ {
+		for j := i + 1; j < size; j++ {
+			fmt.Fprintf(w, "%d|%d\n", pages[i], pages[j])
+		}
+	}
+	w.WriteByte('\n')
+
+	maxLength := size
+	if maxLength > 23 {
+		maxLength = 23
+	}
+	for i := 0; i < size*4; i++ {
+		length := 1 + 2*rng.Intn((maxLength+1)/2)
+		picked := rng.Perm(size)[:length]
+		if i%2 == 0 {
+			sort.Ints(picked)
+		}
+
+		update := make([]int, length)
+		for j, index := range picked {
+			update[j] = pages[index]
+		}
+		writeInts(w, update, ",")
+		w.WriteByte('\n')
+	}
+	return nil
+}
+
+// labMap writes a size x size lab map with scattered obstacles and a guard facing
+// up. Maps where the guard would never leave are rejected and redrawn, so the
+// result is always a valid part 1 input.
+func labMap(w *bufio.Writer, rng *rand.Rand, size int) error {
+	if size < 2 {
+		return fmt.Errorf("day 6 maps must be at least 2x2, got %d", size)
+	}
+
+	for {
+		grid := make([][]byte, size)
+		for row := range grid {
+			grid[row] = make([]byte, size)
+			for col := range grid[row] {
+				grid[row][col] = '.'
+				if rng.Intn(100) < 3 {
+					grid[row][col] = '#'
+				}
+			}
+		}
+
+		guardRow, guardCol := rng.Intn(size), rng.Intn(size)
+		grid[guardRow][guardCol] = '^'
+
+		if !guardEscapes(grid, guardRow, guardCol) {
+			continue
+		}
+
+		for _, row := range grid {
+			w.Write(row)
+			w.WriteByte('\n')
+		}
+		return nil
+	}
+}
+
+// guardEscapes reports whether a guard starting at row, col facing up leaves grid
+func guardEscapes(grid [][]byte, row, col int) bool {
+	deltas := [4][2]int{{-1, 0}, {0, 1}, {1, 0}, {0, -1}}
+	size := len(grid)
+	seen := make(map[[3]int]bool)
+	direction := 0
+
+	for {
+		state := [3]int{row, col, direction}
+		if seen[state] {
+			return false
+		}
+		seen[state] = true
+
+		nextRow, nextCol := row+deltas[direction][0], col+deltas[direction][1]
+		if nextRow < 0 || nextRow >= size || nextCol < 0 || nextCol >= size {
+			return true
+		}
+		if grid[nextRow][nextCol] == '#' {
+			direction = (direction + 1) % 4
+		} else {
+			row, col = nextRow, nextCol
+		}
+	}
+}
+
+// calibrationMaxValue keeps generated test values well inside int64
+const calibrationMaxValue = 1_000_000_000_000_000
+
+// calibrations writes size calibration equations with 2-12 operands. Half of the test
+// values come from applying random +, * and || operators, the rest are random.
+func calibrations(w *bufio.Writer, rng *rand.Rand, size int) error {
+	for i := 0; i < size; i++ {
+		count := 2 + rng.Intn(11)
+		operands := make([]int, count)
+		for j := range operands {
+			operands[j] = 1 + rng.Intn(999)
+		}
+
+		testValue := 1 + rng.Intn(1_000_000)
+		if i%2 == 0 {
+			testValue = operands[0]
+			for _, operand := range operands[1:] {
+				testValue = applyRandomOperator(rng, testValue, operand)
+			}
+		}
+
+		fmt.Fprintf(w, "%d: ", testValue)
+		writeInts(w, operands, " ")
+		w.WriteByte('\n')
+	}
+	return nil
+}
+
+// applyRandomOperator combines a and b with +, * or ||, falling back to + when
+// the other operators would exceed calibrationMaxValue
+func applyRandomOperator(rng *rand.Rand, a, b int) int {
+	switch rng.Intn(3) {
+	case 1:
+		if a <= calibrationMaxValue/b {
+			return a * b
+		}
+	case 2:
+		concatenated, err := strconv.Atoi(strconv.Itoa(a) + strconv.Itoa(b))
+		if err == nil && concatenated <= calibrationMaxValue {
+			return concatenated
+		}
+	}
+	return a + b
+}
+
+func writeInts(w *bufio.Writer, values []int, sep string) {
+	for i, value := range values {
+		if i > 0 {
+			w.WriteString(sep)
+		}
+		w.WriteString(strconv.Itoa(value))
+	}
+}
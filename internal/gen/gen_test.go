@@ -0,0 +1,108 @@
+package gen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"advent-of-code-2024/internal/day01"
+	"advent-of-code-2024/internal/day02"
+	"advent-of-code-2024/internal/day03"
+	"advent-of-code-2024/internal/day04"
+	"advent-of-code-2024/internal/day05"
+	"advent-of-code-2024/internal/day06"
+	"advent-of-code-2024/internal/day07"
+)
+
+type solver func(filename string) (int, error)
+
+var solvers = map[int][2]solver{
+	1: {day01.SolvePart1, day01.SolvePart2},
+	2: {day02.SolvePart1, day02.SolvePart2},
+	3: {day03.SolvePart1, day03.SolvePart2},
+	4: {day04.SolvePart1, day04.SolvePart2},
+	5: {day05.SolvePart1, day05.SolvePart2},
+	6: {day06.SolvePart1, day06.SolvePart2},
+	7: {day07.SolvePart1, day07.SolvePart2},
+}
+
+func TestDays(t *testing.T) {
+	days := Days()
+	if len(days) != len(solvers) {
+		t.Fatalf("Days() = %v, expected %d days", days, len(solvers))
+	}
+	for i, day := range days {
+		if day != i+1 {
+			t.Errorf("Days()[%d] = %d, expected %d", i, day, i+1)
+		}
+	}
+}
+
+func TestGenerateDeterministic(t *testing.T) {
+	for _, day := range Days() {
+		first, err := String(day, 20, 42)
+		if err != nil {
+			t.Fatalf("day %d: String failed: %v", day, err)
+		}
+		second, err := String(day, 20, 42)
+		if err != nil {
+			t.Fatalf("day %d: String failed: %v", day, err)
+		}
+		if first != second {
+			t.Errorf("day %d: same seed produced different output", day)
+		}
+
+		other, err := String(day, 20, 43)
+		if err != nil {
+			t.Fatalf("day %d: String failed: %v", day, err)
+		}
+		if first == other {
+			t.Errorf("day %d: different seeds produced identical output", day)
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		day, size int
+	}{
+		{"Unknown day", 25, 10},
+		{"Zero size", 1, 0},
+		{"Negative size", 3, -5},
+		{"Day 5 with one page", 5, 1},
+		{"Day 6 with one cell", 6, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := String(tt.day, tt.size, 1); err == nil {
+				t.Errorf("String(%d, %d, 1) should return error", tt.day, tt.size)
+			}
+		})
+	}
+}
+
+func TestGeneratedInputsSolve(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, day := range Days() {
+		for seed := int64(1); seed <= 5; seed++ {
+			content, err := String(day, 30, seed)
+			if err != nil {
+				t.Fatalf("day %d seed %d: String failed: %v", day, seed, err)
+			}
+
+			filename := filepath.Join(dir, "input.txt")
+			if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			for part, solve := range solvers[day] {
+				if _, err := solve(filename); err != nil {
+					t.Errorf("day %d part %d seed %d: solver failed on generated input: %v", day, part+1, seed, err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Package trace lets solvers report the reasoning behind their answers as
+// structured events.
+//
+// Solvers call Emit at interesting points (a pair being matched, a report found
+// unsafe, an instruction toggling state). Nothing is recorded unless a Hook has
+// been installed with SetHook, and hot loops should check Enabled before building
+// an event so that tracing costs nothing when it is switched off.
+package trace
+
+import (
+	"sync/atomic"
+)
+
+// Event is a single reasoning step reported by a solver
+type Event struct {
+	Day     int            `json:"day"`
+	Part    int            `json:"part"`
+	Kind    string         `json:"kind"`
+	Message string         `json:"message"`
+	Fields  map[string]any `json:"fields,omitempty"`
+}
+
+// Hook receives every emitted event. Solvers may emit from several goroutines at
+// once, so a Hook must be safe for concurrent use.
+type Hook func(Event)
+
+// puzzle identifies the puzzle whose events are currently being emitted
+type puzzle struct {
+	day, part int
+}
+
+var (
+	hook    atomic.Pointer[Hook]
+	current atomic.Pointer[puzzle]
+)
+
+// SetHook installs h as the receiver for all events. A nil h disables tracing.
+func SetHook(h Hook) {
+	if h == nil {
+		hook.Store(nil)
+		return
+	}
+	hook.Store(&h)
+}
+
+// Enabled reports whether a hook is installed
+func Enabled() bool {
+	return hook.Load() != nil
+}
+
+// Begin marks the start of a puzzle; later events are stamped with its day and part
+func Begin(day, part int) {
+	current.Store(&puzzle{day: day, part: part})
+}
+
+// Emit sends an event of the given kind to the installed hook, if any
+func Emit(kind, message string, fields map[string]any) {
+	h := hook.Load()
+	if h == nil {
+		return
+	}
+
+	event := Event{Kind: kind, Message: message, Fields: fields}
+	if p := current.Load(); p != nil {
+		event.Day, event.Part = p.day, p.part
+	}
+	(*h)(event)
+}
@@ -0,0 +1,71 @@
+package trace
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestEmitWithoutHook(t *testing.T) {
+	SetHook(nil)
+
+	if Enabled() {
+		t.Error("Enabled() = true with no hook installed")
+	}
+
+	// Must not panic
+	Emit("kind", "message", nil)
+}
+
+func TestEmitStampsPuzzle(t *testing.T) {
+	var events []Event
+	SetHook(func(e Event) { events = append(events, e) })
+	defer SetHook(nil)
+
+	Begin(3, 2)
+	Emit("instruction", "mul(2,4) = 8", map[string]any{"product": 8})
+
+	if !Enabled() {
+		t.Error("Enabled() = false with a hook installed")
+	}
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+
+	event := events[0]
+	if event.Day != 3 || event.Part != 2 {
+		t.Errorf("Event stamped with day %d part %d, expected day 3 part 2", event.Day, event.Part)
+	}
+	if event.Kind != "instruction" || event.Message != "mul(2,4) = 8" {
+		t.Errorf("Unexpected event %+v", event)
+	}
+	if event.Fields["product"] != 8 {
+		t.Errorf("Fields[product] = %v, expected 8", event.Fields["product"])
+	}
+}
+
+func TestEmitConcurrent(t *testing.T) {
+	var mu sync.Mutex
+	count := 0
+	SetHook(func(Event) {
+		mu.Lock()
+		count++
+		mu.Unlock()
+	})
+	defer SetHook(nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				Emit("kind", "message", nil)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if count != 1000 {
+		t.Errorf("Expected 1000 events, got %d", count)
+	}
+}
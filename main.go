@@ -3,6 +3,7 @@ package main
 import (
 	"flag"
 	"fmt"
+	"math/big"
 	"os"
 	"path/filepath"
 	"strings"
@@ -15,6 +16,7 @@ import (
 	"advent-of-code-2024/internal/day05"
 	"advent-of-code-2024/internal/day06"
 	"advent-of-code-2024/internal/day07"
+	"advent-of-code-2024/internal/trace"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -30,7 +32,7 @@ const (
 type PuzzleResult struct {
 	Day      int
 	Part     int
-	Result   int
+	Result   *big.Int
 	Duration time.Duration
 	Error    error
 }
@@ -39,7 +41,19 @@ func main() {
 	var day = flag.Int("day", 0, fmt.Sprintf("Run specific day (%d-%d)", MinDay, MaxDay))
 	var part = flag.Int("part", 0, fmt.Sprintf("Run specific part (%d-%d)", MinPart, MaxPart))
 	var debug = flag.Bool("debug", false, "Enable debug mode with detailed output")
+	var view = flag.String("view", "", "Show a day-specific view instead of the results table (requires -day)")
+	var delay = flag.Duration("delay", 0, "Pause between frames for animated views (0 shows only the final frame)")
+	var top = flag.Int("top", 10, "Number of largest entries listed by report views")
+	var words = flag.String("words", "", "Word list file for the day 4 word search views, one word per line (default XMAS)")
+	var lists = flag.String("lists", "", "Comma-separated list files for the day 1 matrix view, each one list per column (default the puzzle input)")
+	var explain = flag.Bool("explain", false, "Print the reasoning steps reported by each solver")
+	var format = flag.String("format", FormatText, fmt.Sprintf("Output format for -explain and views (%s, %s or %s)", FormatText, FormatJSON, FormatHTML))
 	var help = flag.Bool("help", false, "Show help message")
+	day02Flags := registerDay02Flags(flag.CommandLine)
+	day03Flags := registerDay03Flags(flag.CommandLine)
+	day04Flags := registerDay04Flags(flag.CommandLine)
+	day05Flags := registerDay05Flags(flag.CommandLine)
+	overflowFlags := registerOverflowFlags(flag.CommandLine)
 	flag.Parse()
 
 	if *help {
@@ -53,6 +67,55 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := validateFormat(*format); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		fmt.Println("\nUse -help for usage information.")
+		os.Exit(1)
+	}
+	if *explain && *format == FormatHTML {
+		fmt.Printf("Error: -explain supports %s or %s output\n", FormatText, FormatJSON)
+		fmt.Println("\nUse -help for usage information.")
+		os.Exit(1)
+	}
+
+	day02Policy, err := day02Flags.policy(flag.CommandLine)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		fmt.Println("\nUse -help for usage information.")
+		os.Exit(1)
+	}
+	day03Grammar, err := day03Flags.grammar(flag.CommandLine)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		fmt.Println("\nUse -help for usage information.")
+		os.Exit(1)
+	}
+	day04Topology, err := day04Flags.topology(flag.CommandLine)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		fmt.Println("\nUse -help for usage information.")
+		os.Exit(1)
+	}
+	day05Repair, err := day05Flags.strategy(flag.CommandLine)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		fmt.Println("\nUse -help for usage information.")
+		os.Exit(1)
+	}
+	overflow, err := overflowFlags.mode(flag.CommandLine)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		fmt.Println("\nUse -help for usage information.")
+		os.Exit(1)
+	}
+	solveOpts := SolveOptions{
+		Day02Policy:   day02Policy,
+		Day03Grammar:  day03Grammar,
+		Day04Topology: day04Topology,
+		Day05Repair:   day05Repair,
+		Overflow:      overflow,
+	}
+
 	if *debug {
 		zerolog.SetGlobalLevel(zerolog.DebugLevel)
 		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
@@ -60,19 +123,42 @@ func main() {
 		zerolog.SetGlobalLevel(zerolog.InfoLevel)
 	}
 
+	if *view != "" {
+		opts := ViewOptions{Part: *part, Delay: *delay, Format: *format, Top: *top, Words: *words, Lists: splitLists(*lists), Day02Policy: day02Policy, Day03Grammar: day03Grammar}
+		if err := runView(os.Stdout, *day, *view, opts); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	var explainer *explainer
+	if *explain {
+		explainer = newExplainer(os.Stdout, *format)
+		trace.SetHook(explainer.record)
+	}
+
 	start := time.Now()
 
 	var results []PuzzleResult
 
 	if *day != 0 && *part != 0 {
-		results = runSpecificDayPart(*day, *part, *debug)
+		results = runSpecificDayPart(*day, *part, solveOpts, *debug)
 	} else if *day != 0 {
-		results = runSpecificDay(*day, *debug)
+		results = runSpecificDay(*day, solveOpts, *debug)
 	} else {
-		results = runAllDays(*debug)
+		results = runAllDays(solveOpts, *debug)
 	}
 
 	elapsed := time.Since(start)
+
+	if explainer != nil && *format == FormatJSON {
+		explainer.writeResults(results)
+		return
+	}
+	if explainer != nil {
+		fmt.Println()
+	}
 	printResultsTable(results, elapsed, *debug)
 }
 
@@ -170,18 +256,82 @@ func showHelp() {
 	fmt.Printf("  -day int     Run specific day (%d-%d)\n", MinDay, MaxDay)
 	fmt.Printf("  -part int    Run specific part (%d-%d)\n", MinPart, MaxPart)
 	fmt.Println("  -debug       Enable debug mode with detailed output")
+	fmt.Println("  -view name   Show a day-specific view instead of the results table")
+	fmt.Println("  -delay dur   Pause between frames for animated views (e.g. 50ms)")
+	fmt.Println("  -top int     Number of largest entries listed by report views (default 10)")
+	fmt.Println("  -words file  Word list for the day 4 word search views, one word per line (default XMAS)")
+	fmt.Println("  -lists files Comma-separated list files for the day 1 matrix view, one list per column (default the puzzle input)")
+	fmt.Println("  -explain     Print the reasoning steps reported by each solver")
+	fmt.Printf("  -format fmt  Output format for -explain and views (%s, %s or %s)\n", FormatText, FormatJSON, FormatHTML)
 	fmt.Println("  -help        Show this help message")
 	fmt.Println()
+	fmt.Println("Overflow handling for the sums of days 1, 3 and 7:")
+	fmt.Println("  -overflow mode     strict to fail once a sum no longer fits in an int, promote to carry on with math/big (default strict)")
+	fmt.Println()
+	fmt.Println("Day 2 safety rules (override the puzzle's 1-3 steps in one direction):")
+	fmt.Println("  -min-step int      Smallest allowed change between adjacent levels (default 1)")
+	fmt.Println("  -max-step int      Largest allowed change between adjacent levels (default 3)")
+	fmt.Println("  -allow-plateaus    Allow adjacent levels to be equal")
+	fmt.Println("  -any-direction     Allow levels to change direction")
+	fmt.Println("  -dampener int      Levels that may be removed from each report (default 0 for part 1, 1 for part 2)")
+	fmt.Println()
+	fmt.Println("Day 3 instruction grammar (by default any number of digits, no spaces or signs):")
+	fmt.Println("  -min-digits int    Fewest digits in a mul argument (default 1)")
+	fmt.Println("  -max-digits int    Most digits in a mul argument (default 0, no limit; the puzzle says 3)")
+	fmt.Println("  -allow-space       Allow spaces and tabs around instruction arguments")
+	fmt.Println("  -allow-signs       Allow + or - before instruction arguments")
+	fmt.Println()
+	fmt.Println("Day 4 grid topology (by default a rectangle whose edges are walls):")
+	fmt.Println("  -wrap axes         Axes that wrap around: none, rows, cols or both (default none)")
+	fmt.Println("  -ragged            Allow rows of different lengths, each ending at its own last letter")
+	fmt.Println()
+	fmt.Println("Day 5 update repair (by default part 2 reorders every page by the rules):")
+	fmt.Println("  -repair strategy   How part 2 puts invalid updates in order: reorder, or minimal for the fewest page moves")
+	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  ./advent-of-code-2024                    # Run all implemented puzzles")
 	fmt.Println("  ./advent-of-code-2024 -day 1             # Run both parts of day 1")
 	fmt.Println("  ./advent-of-code-2024 -day 1 -part 2     # Run only part 2 of day 1")
 	fmt.Println("  ./advent-of-code-2024 -debug             # Run all puzzles with debug output")
+	fmt.Println("  ./advent-of-code-2024 -day 2 -explain    # Explain why each day 2 report is safe or unsafe")
+	fmt.Println("  ./advent-of-code-2024 -day 7 -part 2 -explain -format json")
+	fmt.Println("  ./advent-of-code-2024 -day 2 -max-step 4 -dampener 2   # Day 2 under looser sensor tolerances")
+	fmt.Println("  ./advent-of-code-2024 -day 3 -max-digits 3             # Day 3 with the puzzle's 1-3 digit arguments")
+	fmt.Println("  ./advent-of-code-2024 -day 7 -overflow promote         # Day 7 sums past the range of an int")
+	fmt.Println("  ./advent-of-code-2024 -day 4 -wrap both                # Day 4 on a torus")
+	fmt.Println("  ./advent-of-code-2024 -day 5 -part 2 -repair minimal   # Day 5 moving as few pages as possible")
+	fmt.Println()
+	fmt.Println("Views:")
+	for day := MinDay; day <= MaxDay; day++ {
+		if names := viewNames(day); len(names) > 0 {
+			fmt.Printf("  Day %d: %s\n", day, strings.Join(names, ", "))
+		}
+	}
+	fmt.Println()
+	fmt.Println("View examples:")
+	fmt.Println("  ./advent-of-code-2024 -day 1 -view report -top 5            # Pairs, top distances and frequencies")
+	fmt.Println("  ./advent-of-code-2024 -day 1 -view report -format json")
+	fmt.Println("  ./advent-of-code-2024 -day 1 -view matrix                   # Pairwise distance and similarity matrices")
+	fmt.Println("  ./advent-of-code-2024 -day 1 -view matrix -lists a.txt,b.txt,c.txt")
+	fmt.Println("  ./advent-of-code-2024 -day 2 -view diagnose                 # Why each unsafe report fails")
+	fmt.Println("  ./advent-of-code-2024 -day 2 -view diagnose -max-step 4     # Diagnose under a custom policy")
+	fmt.Println("  ./advent-of-code-2024 -day 3 -view annotate                 # Highlight the instructions part 2 counted")
+	fmt.Println("  ./advent-of-code-2024 -day 3 -part 1 -view annotate -format html > day03.html")
+	fmt.Println("  ./advent-of-code-2024 -day 3 -view audit                    # Near-miss instructions the 1-3 digit rule rejects")
+	fmt.Println("  ./advent-of-code-2024 -day 3 -view audit -allow-space       # Audit under a custom grammar")
+	fmt.Println("  ./advent-of-code-2024 -day 4 -view words -words list.txt    # How often each listed word occurs")
+	fmt.Println("  ./advent-of-code-2024 -day 4 -view matches -words list.txt  # Start cell and direction of every match")
+	fmt.Println("  ./advent-of-code-2024 -day 5 -view violations -top 5        # Rules broken most often, pages to move")
+	fmt.Println("  ./advent-of-code-2024 -day 5 -view rules                    # Cycles, redundant rules and any total order")
+	fmt.Println("  ./advent-of-code-2024 -day 5 -view repairs                  # Fewest page moves, part 2 under both repairs")
+	fmt.Println("  ./advent-of-code-2024 -day 6 -view visualize                # Final frame of the guard's patrol")
+	fmt.Println("  ./advent-of-code-2024 -day 6 -view visualize -delay 20ms    # Play the patrol back step by step")
+	fmt.Println("  ./advent-of-code-2024 -day 6 -part 2 -view visualize        # Mark loop-causing obstacles with O")
 }
 
-func runSpecificDayPart(day, part int, debug bool) []PuzzleResult {
+func runSpecificDayPart(day, part int, opts SolveOptions, debug bool) []PuzzleResult {
 	start := time.Now()
-	result, err := solveDayPart(day, part)
+	result, err := solveDayPart(day, part, opts)
 	duration := time.Since(start)
 
 	return []PuzzleResult{{
@@ -193,12 +343,12 @@ func runSpecificDayPart(day, part int, debug bool) []PuzzleResult {
 	}}
 }
 
-func runSpecificDay(day int, debug bool) []PuzzleResult {
+func runSpecificDay(day int, opts SolveOptions, debug bool) []PuzzleResult {
 	var results []PuzzleResult
 
 	// Run part 1
 	start1 := time.Now()
-	result1, err1 := solveDayPart(day, MinPart)
+	result1, err1 := solveDayPart(day, MinPart, opts)
 	duration1 := time.Since(start1)
 
 	results = append(results, PuzzleResult{
@@ -211,7 +361,7 @@ func runSpecificDay(day int, debug bool) []PuzzleResult {
 
 	// Run part 2
 	start2 := time.Now()
-	result2, err2 := solveDayPart(day, MaxPart)
+	result2, err2 := solveDayPart(day, MaxPart, opts)
 	duration2 := time.Since(start2)
 
 	results = append(results, PuzzleResult{
@@ -225,7 +375,7 @@ func runSpecificDay(day int, debug bool) []PuzzleResult {
 	return results
 }
 
-func runAllDays(debug bool) []PuzzleResult {
+func runAllDays(opts SolveOptions, debug bool) []PuzzleResult {
 	var results []PuzzleResult
 	for day := MinDay; day <= MaxDay; day++ {
 		// Check if input file exists before running any parts for this day
@@ -236,7 +386,7 @@ func runAllDays(debug bool) []PuzzleResult {
 
 		for part := MinPart; part <= MaxPart; part++ {
 			start := time.Now()
-			result, err := solveDayPart(day, part)
+			result, err := solveDayPart(day, part, opts)
 			duration := time.Since(start)
 
 			// Only add results for implemented puzzles (those that don't return "puzzle not implemented")
@@ -255,30 +405,83 @@ func runAllDays(debug bool) []PuzzleResult {
 	return results
 }
 
-func solveDayPart(day, part int) (int, error) {
+// solveDayPart solves one part. The answer is a *big.Int so that -overflow
+// promote can report sums past the range of an int.
+func solveDayPart(day, part int, opts SolveOptions) (*big.Int, error) {
+	trace.Begin(day, part)
 	inputFile := getInputFilePath(day)
 
 	if _, err := os.Stat(inputFile); os.IsNotExist(err) {
-		return 0, fmt.Errorf("input file does not exist: %s", inputFile)
+		return nil, fmt.Errorf("input file does not exist: %s", inputFile)
 	}
 
+	if opts.Overflow != nil {
+		if result, ok, err := solveWithOverflow(day, part, inputFile, opts); ok {
+			return result, err
+		}
+	}
+
+	result, err := solveIntPart(day, part, inputFile, opts)
+	if err != nil {
+		return nil, err
+	}
+	return big.NewInt(int64(result)), nil
+}
+
+// solveWithOverflow solves the parts whose sums can outgrow an int under
+// opts.Overflow, reporting false for the parts that have no overflow mode
+func solveWithOverflow(day, part int, inputFile string, opts SolveOptions) (*big.Int, bool, error) {
+	mode := *opts.Overflow
+	var result *big.Int
+	var err error
+	switch {
+	case day == 1 && part == 1:
+		result, err = day01.SolvePart1Mode(inputFile, mode)
+	case day == 1 && part == 2:
+		result, err = day01.SolvePart2Mode(inputFile, mode)
+	case day == 3 && opts.Day03Grammar != nil:
+		result, err = day03.SolveWithGrammarMode(inputFile, part, *opts.Day03Grammar, mode)
+	case day == 3 && part == 1:
+		result, err = day03.SolvePart1Mode(inputFile, mode)
+	case day == 3 && part == 2:
+		result, err = day03.SolvePart2Mode(inputFile, mode)
+	case day == 7 && part == 1:
+		result, err = day07.SolvePart1Mode(inputFile, mode)
+	case day == 7 && part == 2:
+		result, err = day07.SolvePart2Mode(inputFile, mode)
+	default:
+		return nil, false, nil
+	}
+	return result, true, err
+}
+
+// solveIntPart solves one part with the solvers that answer with an int
+func solveIntPart(day, part int, inputFile string, opts SolveOptions) (int, error) {
 	switch {
 	case day == 1 && part == 1:
 		return day01.SolvePart1(inputFile)
 	case day == 1 && part == 2:
 		return day01.SolvePart2(inputFile)
+	case day == 2 && opts.Day02Policy != nil:
+		return solveDay02WithPolicy(inputFile, part, *opts.Day02Policy)
 	case day == 2 && part == 1:
 		return day02.SolvePart1(inputFile)
 	case day == 2 && part == 2:
 		return day02.SolvePart2(inputFile)
+	case day == 3 && opts.Day03Grammar != nil:
+		return day03.SolveWithGrammar(inputFile, part, *opts.Day03Grammar)
 	case day == 3 && part == 1:
 		return day03.SolvePart1(inputFile)
 	case day == 3 && part == 2:
 		return day03.SolvePart2(inputFile)
+	case day == 4 && opts.Day04Topology != nil:
+		return day04.SolveWithTopology(inputFile, part, *opts.Day04Topology)
 	case day == 4 && part == 1:
 		return day04.SolvePart1(inputFile)
 	case day == 4 && part == 2:
 		return day04.SolvePart2(inputFile)
+	case day == 5 && part == 2 && opts.Day05Repair != nil:
+		return day05.SolvePart2With(inputFile, *opts.Day05Repair)
 	case day == 5 && part == 1:
 		return day05.SolvePart1(inputFile)
 	case day == 5 && part == 2:
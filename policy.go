@@ -0,0 +1,244 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"advent-of-code-2024/internal/checked"
+	"advent-of-code-2024/internal/day02"
+	"advent-of-code-2024/internal/day03"
+	"advent-of-code-2024/internal/day04"
+	"advent-of-code-2024/internal/day05"
+)
+
+// SolveOptions carries command-line settings that change how puzzles are solved
+type SolveOptions struct {
+	// Day02Policy replaces the day 2 safety rules when set. A negative
+	// DampenerBudget keeps each part's own budget: none for part 1, one for part 2.
+	Day02Policy *day02.SafetyPolicy
+
+	// Day03Grammar restricts how day 3 instructions may be spelled when set
+	Day03Grammar *day03.Grammar
+
+	// Day04Topology changes how the edges of the day 4 grid behave when set
+	Day04Topology *day04.Topology
+
+	// Day05Repair chooses how day 5 part 2 puts invalid updates in order when set
+	Day05Repair *day05.RepairStrategy
+
+	// Overflow chooses what happens when a day 1, 3 or 7 sum overflows an int
+	// when set. The int solvers otherwise fail, as checked.Strict does.
+	Overflow *checked.Mode
+}
+
+// day02Flags are the command-line overrides for the day 2 safety rules
+type day02Flags struct {
+	minStep       *int
+	maxStep       *int
+	allowPlateaus *bool
+	anyDirection  *bool
+	dampener      *int
+}
+
+var day02FlagNames = []string{"min-step", "max-step", "allow-plateaus", "any-direction", "dampener"}
+
+func registerDay02Flags(fs *flag.FlagSet) *day02Flags {
+	puzzle := day02.PuzzlePolicy()
+	return &day02Flags{
+		minStep:       fs.Int("min-step", puzzle.MinStep, "Day 2: smallest allowed change between adjacent levels"),
+		maxStep:       fs.Int("max-step", puzzle.MaxStep, "Day 2: largest allowed change between adjacent levels"),
+		allowPlateaus: fs.Bool("allow-plateaus", false, "Day 2: allow adjacent levels to be equal"),
+		anyDirection:  fs.Bool("any-direction", false, "Day 2: allow levels to change direction"),
+		dampener:      fs.Int("dampener", -1, "Day 2: levels the dampener may remove from each report (-1 uses 0 for part 1, 1 for part 2)"),
+	}
+}
+
+// policy builds the day 2 policy from the flags, or returns nil if none of them
+// were set on fs so that the puzzle's own rules apply
+func (f *day02Flags) policy(fs *flag.FlagSet) (*day02.SafetyPolicy, error) {
+	set := false
+	fs.Visit(func(fl *flag.Flag) {
+		for _, name := range day02FlagNames {
+			set = set || fl.Name == name
+		}
+	})
+	if !set {
+		return nil, nil
+	}
+
+	policy := day02.SafetyPolicy{
+		MinStep:          *f.minStep,
+		MaxStep:          *f.maxStep,
+		AllowPlateaus:    *f.allowPlateaus,
+		RequireDirection: !*f.anyDirection,
+		DampenerBudget:   *f.dampener,
+	}
+
+	// Check the bounds with the budget the first part would use
+	check := policy
+	check.DampenerBudget = max(check.DampenerBudget, 0)
+	if err := check.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid day 2 policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// solveDay02WithPolicy solves a day 2 part under policy, filling in the part's
+// own dampener budget if the policy leaves it unset
+func solveDay02WithPolicy(inputFile string, part int, policy day02.SafetyPolicy) (int, error) {
+	if policy.DampenerBudget < 0 {
+		policy.DampenerBudget = part - 1
+	}
+	return day02.SolveWithPolicy(inputFile, policy)
+}
+
+// day03Flags are the command-line settings for the day 3 instruction grammar
+type day03Flags struct {
+	minDigits  *int
+	maxDigits  *int
+	allowSpace *bool
+	allowSigns *bool
+}
+
+var day03FlagNames = []string{"min-digits", "max-digits", "allow-space", "allow-signs"}
+
+// day03Space is the whitespace -allow-space permits
+const day03Space = " \t"
+
+func registerDay03Flags(fs *flag.FlagSet) *day03Flags {
+	return &day03Flags{
+		minDigits:  fs.Int("min-digits", 1, "Day 3: fewest digits in a mul argument"),
+		maxDigits:  fs.Int("max-digits", 0, "Day 3: most digits in a mul argument (0 for no limit, 3 for the puzzle's rule)"),
+		allowSpace: fs.Bool("allow-space", false, "Day 3: allow spaces and tabs around instruction arguments"),
+		allowSigns: fs.Bool("allow-signs", false, "Day 3: allow + or - before instruction arguments"),
+	}
+}
+
+// grammar builds the day 3 grammar from the flags, or returns nil if none of
+// them were set on fs so that the solvers' own rules apply
+func (f *day03Flags) grammar(fs *flag.FlagSet) (*day03.Grammar, error) {
+	set := false
+	fs.Visit(func(fl *flag.Flag) {
+		for _, name := range day03FlagNames {
+			set = set || fl.Name == name
+		}
+	})
+	if !set {
+		return nil, nil
+	}
+
+	grammar := day03.Grammar{
+		MinDigits:  *f.minDigits,
+		MaxDigits:  *f.maxDigits,
+		AllowSigns: *f.allowSigns,
+	}
+	if *f.allowSpace {
+		grammar.Space = day03Space
+	}
+
+	if err := grammar.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid day 3 grammar: %w", err)
+	}
+	return &grammar, nil
+}
+
+// day04Flags are the command-line settings for the day 4 grid topology
+type day04Flags struct {
+	wrap   *string
+	ragged *bool
+}
+
+var day04FlagNames = []string{"wrap", "ragged"}
+
+func registerDay04Flags(fs *flag.FlagSet) *day04Flags {
+	return &day04Flags{
+		wrap:   fs.String("wrap", "none", "Day 4: grid axes that wrap around (none, rows, cols or both)"),
+		ragged: fs.Bool("ragged", false, "Day 4: allow grid rows of different lengths"),
+	}
+}
+
+// topology builds the day 4 topology from the flags, or returns nil if none of
+// them were set on fs so that the grid is the puzzle's rectangle
+func (f *day04Flags) topology(fs *flag.FlagSet) (*day04.Topology, error) {
+	set := false
+	fs.Visit(func(fl *flag.Flag) {
+		for _, name := range day04FlagNames {
+			set = set || fl.Name == name
+		}
+	})
+	if !set {
+		return nil, nil
+	}
+
+	topology := day04.Topology{Ragged: *f.ragged}
+	if err := topology.ParseWrap(*f.wrap); err != nil {
+		return nil, fmt.Errorf("invalid day 4 topology: %w", err)
+	}
+	return &topology, nil
+}
+
+// day05Flags are the command-line settings for repairing day 5 updates
+type day05Flags struct {
+	repair *string
+}
+
+var day05FlagNames = []string{"repair"}
+
+func registerDay05Flags(fs *flag.FlagSet) *day05Flags {
+	return &day05Flags{
+		repair: fs.String("repair", string(day05.RepairReorder), fmt.Sprintf("Day 5: how part 2 puts invalid updates in order (%s or %s)", day05.RepairReorder, day05.RepairMinimal)),
+	}
+}
+
+// strategy reads the day 5 repair strategy from the flags, or returns nil if
+// none of them were set on fs so that part 2 reorders as the puzzle does
+func (f *day05Flags) strategy(fs *flag.FlagSet) (*day05.RepairStrategy, error) {
+	set := false
+	fs.Visit(func(fl *flag.Flag) {
+		for _, name := range day05FlagNames {
+			set = set || fl.Name == name
+		}
+	})
+	if !set {
+		return nil, nil
+	}
+
+	strategy, err := day05.ParseRepairStrategy(*f.repair)
+	if err != nil {
+		return nil, fmt.Errorf("invalid day 5 repair: %w", err)
+	}
+	return &strategy, nil
+}
+
+// overflowFlags are the command-line settings for overflow handling
+type overflowFlags struct {
+	overflow *string
+}
+
+var overflowFlagNames = []string{"overflow"}
+
+func registerOverflowFlags(fs *flag.FlagSet) *overflowFlags {
+	return &overflowFlags{
+		overflow: fs.String("overflow", checked.Strict.String(), fmt.Sprintf("Days 1, 3 and 7: what happens when a sum overflows an int (%s or %s)", checked.Strict, checked.Promote)),
+	}
+}
+
+// mode reads the overflow mode from the flags, or returns nil if none of them
+// were set on fs so that the solvers keep plain int arithmetic
+func (f *overflowFlags) mode(fs *flag.FlagSet) (*checked.Mode, error) {
+	set := false
+	fs.Visit(func(fl *flag.Flag) {
+		for _, name := range overflowFlagNames {
+			set = set || fl.Name == name
+		}
+	})
+	if !set {
+		return nil, nil
+	}
+
+	mode, err := checked.ParseMode(*f.overflow)
+	if err != nil {
+		return nil, fmt.Errorf("invalid overflow mode: %w", err)
+	}
+	return &mode, nil
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"advent-of-code-2024/internal/checked"
+	"advent-of-code-2024/internal/day02"
+	"advent-of-code-2024/internal/day03"
+	"advent-of-code-2024/internal/day04"
+	"advent-of-code-2024/internal/day05"
+)
+
+func parseDay02Flags(t *testing.T, args ...string) (*day02.SafetyPolicy, error) {
+	t.Helper()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := registerDay02Flags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return flags.policy(fs)
+}
+
+func TestDay02PolicyFlags(t *testing.T) {
+	policy, err := parseDay02Flags(t)
+	if err != nil || policy != nil {
+		t.Errorf("policy() with no flags = %+v, %v, expected nil", policy, err)
+	}
+
+	policy, err = parseDay02Flags(t, "-max-step", "5", "-allow-plateaus", "-any-direction")
+	if err != nil {
+		t.Fatalf("policy() error = %v", err)
+	}
+	expected := day02.SafetyPolicy{MinStep: 1, MaxStep: 5, AllowPlateaus: true, DampenerBudget: -1}
+	if *policy != expected {
+		t.Errorf("policy() = %+v, expected %+v", *policy, expected)
+	}
+
+	for _, args := range [][]string{
+		{"-min-step", "0"},
+		{"-min-step", "4"},
+	} {
+		if _, err := parseDay02Flags(t, args...); err == nil {
+			t.Errorf("policy() with %v expected error", args)
+		}
+	}
+}
+
+func TestSolveDay02WithPolicy(t *testing.T) {
+	inputFile := filepath.Join("internal", "day02", "example-input.txt")
+
+	tests := []struct {
+		name     string
+		policy   day02.SafetyPolicy
+		part     int
+		expected int
+	}{
+		{"Puzzle rules part 1", day02.SafetyPolicy{MinStep: 1, MaxStep: 3, RequireDirection: true, DampenerBudget: -1}, 1, 2},
+		{"Puzzle rules part 2", day02.SafetyPolicy{MinStep: 1, MaxStep: 3, RequireDirection: true, DampenerBudget: -1}, 2, 4},
+		{"Wider steps", day02.SafetyPolicy{MinStep: 1, MaxStep: 5, RequireDirection: true, DampenerBudget: -1}, 1, 4},
+		{"Fixed budget ignores part", day02.SafetyPolicy{MinStep: 1, MaxStep: 3, RequireDirection: true, DampenerBudget: 2}, 1, 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := solveDay02WithPolicy(inputFile, tt.part, tt.policy)
+			if err != nil {
+				t.Fatalf("solveDay02WithPolicy() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("solveDay02WithPolicy() = %d, expected %d", result, tt.expected)
+			}
+		})
+	}
+}
+
+func parseDay03Flags(t *testing.T, args ...string) (*day03.Grammar, error) {
+	t.Helper()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := registerDay03Flags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return flags.grammar(fs)
+}
+
+func TestDay03GrammarFlags(t *testing.T) {
+	grammar, err := parseDay03Flags(t)
+	if err != nil || grammar != nil {
+		t.Errorf("grammar() with no flags = %+v, %v, expected nil", grammar, err)
+	}
+
+	grammar, err = parseDay03Flags(t, "-max-digits", "3", "-allow-space", "-allow-signs")
+	if err != nil {
+		t.Fatalf("grammar() error = %v", err)
+	}
+	expected := day03.Grammar{MinDigits: 1, MaxDigits: 3, Space: " \t", AllowSigns: true}
+	if *grammar != expected {
+		t.Errorf("grammar() = %+v, expected %+v", *grammar, expected)
+	}
+
+	if _, err := parseDay03Flags(t, "-min-digits", "4", "-max-digits", "3"); err == nil {
+		t.Error("grammar() with -min-digits above -max-digits expected error")
+	}
+}
+
+func parseDay04Flags(t *testing.T, args ...string) (*day04.Topology, error) {
+	t.Helper()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := registerDay04Flags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return flags.topology(fs)
+}
+
+func TestDay04TopologyFlags(t *testing.T) {
+	topology, err := parseDay04Flags(t)
+	if err != nil || topology != nil {
+		t.Errorf("topology() with no flags = %+v, %v, expected nil", topology, err)
+	}
+
+	topology, err = parseDay04Flags(t, "-wrap", "cols", "-ragged")
+	if err != nil {
+		t.Fatalf("topology() error = %v", err)
+	}
+	expected := day04.Topology{WrapCols: true, Ragged: true}
+	if *topology != expected {
+		t.Errorf("topology() = %+v, expected %+v", *topology, expected)
+	}
+
+	if _, err := parseDay04Flags(t, "-wrap", "diagonal"); err == nil {
+		t.Error("topology() with an unknown -wrap expected error")
+	}
+}
+
+func parseDay05Flags(t *testing.T, args ...string) (*day05.RepairStrategy, error) {
+	t.Helper()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := registerDay05Flags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return flags.strategy(fs)
+}
+
+func TestDay05RepairFlags(t *testing.T) {
+	strategy, err := parseDay05Flags(t)
+	if err != nil || strategy != nil {
+		t.Errorf("strategy() with no flags = %v, %v, expected nil", strategy, err)
+	}
+
+	strategy, err = parseDay05Flags(t, "-repair", "minimal")
+	if err != nil {
+		t.Fatalf("strategy() error = %v", err)
+	}
+	if *strategy != day05.RepairMinimal {
+		t.Errorf("strategy() = %s, expected %s", *strategy, day05.RepairMinimal)
+	}
+
+	if _, err := parseDay05Flags(t, "-repair", "shuffle"); err == nil {
+		t.Error("strategy() with an unknown -repair expected error")
+	}
+}
+
+func parseOverflowFlags(t *testing.T, args ...string) (*checked.Mode, error) {
+	t.Helper()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := registerOverflowFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return flags.mode(fs)
+}
+
+func TestOverflowFlags(t *testing.T) {
+	mode, err := parseOverflowFlags(t)
+	if err != nil || mode != nil {
+		t.Errorf("mode() with no flags = %v, %v, expected nil", mode, err)
+	}
+
+	mode, err = parseOverflowFlags(t, "-overflow", "promote")
+	if err != nil {
+		t.Fatalf("mode() error = %v", err)
+	}
+	if *mode != checked.Promote {
+		t.Errorf("mode() = %s, expected %s", *mode, checked.Promote)
+	}
+
+	if _, err := parseOverflowFlags(t, "-overflow", "wrap"); err == nil {
+		t.Error("mode() with an unknown -overflow expected error")
+	}
+}
+
+func TestSolveWithOverflow(t *testing.T) {
+	// The two distances add up to twice MaxInt, which only promote can report
+	path := filepath.Join(t.TempDir(), "input.txt")
+	input := fmt.Sprintf("0   %d\n0   %d\n", math.MaxInt, math.MaxInt)
+	if err := os.WriteFile(path, []byte(input), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	strict, promote := checked.Strict, checked.Promote
+	if _, _, err := solveWithOverflow(1, 1, path, SolveOptions{Overflow: &strict}); !errors.Is(err, checked.ErrOverflow) {
+		t.Errorf("solveWithOverflow() in strict mode error = %v, expected %v", err, checked.ErrOverflow)
+	}
+
+	result, ok, err := solveWithOverflow(1, 1, path, SolveOptions{Overflow: &promote})
+	expected := new(big.Int).Mul(big.NewInt(math.MaxInt), big.NewInt(2))
+	if !ok || err != nil || result.Cmp(expected) != 0 {
+		t.Errorf("solveWithOverflow() in promote mode = %v, %v, %v, expected %v", result, ok, err, expected)
+	}
+
+	if _, ok, _ := solveWithOverflow(2, 1, path, SolveOptions{Overflow: &promote}); ok {
+		t.Error("solveWithOverflow() for day 2 = ok, expected no overflow mode")
+	}
+}
+
+func TestSolveWithOverflowAndGrammar(t *testing.T) {
+	// -max-digits 3 rejects the first mul, and -overflow must not bring it back
+	path := filepath.Join(t.TempDir(), "input.txt")
+	if err := os.WriteFile(path, []byte("mul(1234,2)mul(2,3)"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	promote := checked.Promote
+	opts := SolveOptions{Day03Grammar: &day03.Grammar{MaxDigits: 3}, Overflow: &promote}
+	for part := 1; part <= 2; part++ {
+		result, ok, err := solveWithOverflow(3, part, path, opts)
+		if !ok || err != nil || result.Cmp(big.NewInt(6)) != 0 {
+			t.Errorf("solveWithOverflow() part %d = %v, %v, %v, expected 6", part, result, ok, err)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"advent-of-code-2024/internal/day01"
+	"advent-of-code-2024/internal/day02"
+	"advent-of-code-2024/internal/day03"
+	"advent-of-code-2024/internal/day04"
+	"advent-of-code-2024/internal/day05"
+	"advent-of-code-2024/internal/day06"
+)
+
+// ViewOptions carries the command-line settings that day-specific views understand
+type ViewOptions struct {
+	Part   int
+	Delay  time.Duration
+	Format string
+	Top    int
+	// Words is a word list file for the day 4 word search views
+	Words string
+	// Lists are the files the day 1 matrix view compares instead of the
+	// puzzle input's two columns
+	Lists []string
+
+	// Day02Policy replaces the day 2 safety rules when set
+	Day02Policy *day02.SafetyPolicy
+	// Day03Grammar replaces the puzzle's grammar for the day 3 audit when set
+	Day03Grammar *day03.Grammar
+}
+
+// viewFunc renders an alternative, day-specific view of a puzzle input instead of
+// the results table
+type viewFunc func(w io.Writer, inputFile string, opts ViewOptions) error
+
+// views lists the available views for each day by name
+var views = map[int]map[string]viewFunc{
+	1: {
+		"matrix": matrixDay01,
+		"report": reportDay01,
+	},
+	2: {
+		"diagnose": diagnoseDay02,
+	},
+	3: {
+		"annotate": annotateDay03,
+		"audit":    auditDay03,
+	},
+	4: {
+		"matches": matchesDay04,
+		"words":   wordsDay04,
+	},
+	5: {
+		"repairs":    repairsDay05,
+		"rules":      rulesDay05,
+		"violations": violationsDay05,
+	},
+	6: {
+		"visualize": visualizeDay06,
+	},
+}
+
+// viewNames returns the sorted view names for day
+func viewNames(day int) []string {
+	var names []string
+	for name := range views[day] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func validateView(day int, view string) error {
+	if day == 0 {
+		return fmt.Errorf("cannot specify view without day")
+	}
+
+	if _, ok := views[day][view]; !ok {
+		names := viewNames(day)
+		if len(names) == 0 {
+			return fmt.Errorf("day %d has no views", day)
+		}
+		return fmt.Errorf("unknown view %q for day %d (available: %s)", view, day, strings.Join(names, ", "))
+	}
+
+	return nil
+}
+
+func runView(w io.Writer, day int, view string, opts ViewOptions) error {
+	if err := validateView(day, view); err != nil {
+		return err
+	}
+
+	return views[day][view](w, getInputFilePath(day), opts)
+}
+
+func visualizeDay06(w io.Writer, inputFile string, opts ViewOptions) error {
+	// The patrol is drawn as text frames, which have no JSON or HTML form
+	if opts.Format == FormatJSON || opts.Format == FormatHTML {
+		return fmt.Errorf("the visualize view has only %s output", FormatText)
+	}
+	return day06.Visualize(w, inputFile, day06.VisualizeOptions{Part: opts.Part, Delay: opts.Delay})
+}
+
+func reportDay01(w io.Writer, inputFile string, opts ViewOptions) error {
+	report, err := day01.BuildReport(inputFile, opts.Top)
+	if err != nil {
+		return err
+	}
+
+	return writeView(w, opts.Format, report, report.WriteTable)
+}
+
+// matrixDay01 compares the lists in opts.Lists, or the puzzle input's columns
+func matrixDay01(w io.Writer, inputFile string, opts ViewOptions) error {
+	var lists [][]int
+	var err error
+	if len(opts.Lists) > 0 {
+		lists, err = day01.ReadLists(opts.Lists...)
+	} else {
+		lists, err = day01.ReadColumns(inputFile)
+	}
+	if err != nil {
+		return err
+	}
+
+	matrices, err := day01.CompareLists(lists)
+	if err != nil {
+		return err
+	}
+
+	return writeView(w, opts.Format, matrices, matrices.WriteTable)
+}
+
+func diagnoseDay02(w io.Writer, inputFile string, opts ViewOptions) error {
+	policy := day02.PuzzlePolicy()
+	if opts.Day02Policy != nil {
+		// Diagnoses always consider a single removal, so an unset budget is fine
+		policy = *opts.Day02Policy
+		policy.DampenerBudget = max(policy.DampenerBudget, 0)
+	}
+
+	summary, err := day02.DiagnoseFile(inputFile, policy)
+	if err != nil {
+		return err
+	}
+
+	return writeView(w, opts.Format, summary, summary.WriteTable)
+}
+
+// annotateDay03 shows the program as part 2 runs it unless -part 1 is given
+func annotateDay03(w io.Writer, inputFile string, opts ViewOptions) error {
+	part := opts.Part
+	if part == 0 {
+		part = 2
+	}
+
+	program, err := day03.AnnotateFile(inputFile, part)
+	if err != nil {
+		return err
+	}
+
+	if opts.Format == FormatHTML {
+		return program.WriteHTML(w)
+	}
+	return writeView(w, opts.Format, program, program.WriteANSI)
+}
+
+// auditDay03 lists the calls that the puzzle's grammar, or the one given on the
+// command line, rejects
+func auditDay03(w io.Writer, inputFile string, opts ViewOptions) error {
+	grammar := day03.PuzzleGrammar()
+	if opts.Day03Grammar != nil {
+		grammar = *opts.Day03Grammar
+	}
+
+	part := opts.Part
+	if part == 0 {
+		part = 2
+	}
+
+	audit, err := day03.AuditFile(inputFile, part, grammar)
+	if err != nil {
+		return err
+	}
+
+	return writeView(w, opts.Format, audit, audit.WriteTable)
+}
+
+// searchDay04 searches for the words in opts.Words, or just XMAS
+func searchDay04(inputFile string, opts ViewOptions) (*day04.SearchReport, error) {
+	words := []string{"XMAS"}
+	if opts.Words != "" {
+		var err error
+		if words, err = day04.ReadWordList(opts.Words); err != nil {
+			return nil, err
+		}
+	}
+
+	return day04.SearchFile(inputFile, words)
+}
+
+func wordsDay04(w io.Writer, inputFile string, opts ViewOptions) error {
+	report, err := searchDay04(inputFile, opts)
+	if err != nil {
+		return err
+	}
+
+	return writeView(w, opts.Format, report.Counts, report.WriteCounts)
+}
+
+func matchesDay04(w io.Writer, inputFile string, opts ViewOptions) error {
+	report, err := searchDay04(inputFile, opts)
+	if err != nil {
+		return err
+	}
+
+	return writeView(w, opts.Format, report.Matches, report.WriteMatches)
+}
+
+// violationsDay05 lists the rules broken most often and what each invalid
+// update needs moved
+func violationsDay05(w io.Writer, inputFile string, opts ViewOptions) error {
+	summary, err := day05.SummariseViolationsFile(inputFile, opts.Top)
+	if err != nil {
+		return err
+	}
+
+	return writeView(w, opts.Format, summary, summary.WriteTable)
+}
+
+// rulesDay05 checks the rules on their own: cycles, redundant rules, pages
+// left unordered and the total order if there is one
+func rulesDay05(w io.Writer, inputFile string, opts ViewOptions) error {
+	analysis, err := day05.AnalyseRulesFile(inputFile, opts.Top)
+	if err != nil {
+		return err
+	}
+
+	return writeView(w, opts.Format, analysis, analysis.WriteTable)
+}
+
+// repairsDay05 lists the fewest page moves that put each invalid update in
+// order, and the part 2 answer under both repair strategies
+func repairsDay05(w io.Writer, inputFile string, opts ViewOptions) error {
+	report, err := day05.RepairFile(inputFile)
+	if err != nil {
+		return err
+	}
+
+	return writeView(w, opts.Format, report, report.WriteTable)
+}
+
+// splitLists splits the comma-separated -lists flag into file names
+func splitLists(flag string) []string {
+	var lists []string
+	for _, name := range strings.Split(flag, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			lists = append(lists, name)
+		}
+	}
+	return lists
+}
+
+// writeView writes value as indented JSON when format is json, and otherwise
+// calls writeTable. Views with HTML output handle that format themselves.
+func writeView(w io.Writer, format string, value any, writeTable func(io.Writer) error) error {
+	if format == FormatHTML {
+		return fmt.Errorf("this view has no %s output", FormatHTML)
+	}
+	if format == FormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+	return writeTable(w)
+}
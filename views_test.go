@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"advent-of-code-2024/internal/day01"
+	"advent-of-code-2024/internal/day02"
+	"advent-of-code-2024/internal/day03"
+	"advent-of-code-2024/internal/day04"
+	"advent-of-code-2024/internal/day05"
+)
+
+func TestValidateView(t *testing.T) {
+	tests := []struct {
+		name    string
+		day     int
+		view    string
+		wantErr bool
+	}{
+		{"Valid: day 1 report", 1, "report", false},
+		{"Valid: day 1 matrix", 1, "matrix", false},
+		{"Valid: day 2 diagnose", 2, "diagnose", false},
+		{"Valid: day 3 annotate", 3, "annotate", false},
+		{"Valid: day 3 audit", 3, "audit", false},
+		{"Valid: day 4 words", 4, "words", false},
+		{"Valid: day 4 matches", 4, "matches", false},
+		{"Valid: day 6 visualize", 6, "visualize", false},
+		{"Invalid: no day", 0, "visualize", true},
+		{"Invalid: unknown view", 6, "bogus", true},
+		{"Invalid: day without views", 25, "visualize", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateView(tt.day, tt.view)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateView() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestViewNamesSorted(t *testing.T) {
+	for day, dayViews := range views {
+		names := viewNames(day)
+		if len(names) != len(dayViews) {
+			t.Errorf("viewNames(%d) = %v, expected %d names", day, names, len(dayViews))
+		}
+		for i := 1; i < len(names); i++ {
+			if names[i-1] > names[i] {
+				t.Errorf("viewNames(%d) = %v is not sorted", day, names)
+			}
+		}
+	}
+}
+
+func TestWriteView(t *testing.T) {
+	value := map[string]int{"total": 11}
+	writeTable := func(w io.Writer) error {
+		_, err := io.WriteString(w, "table\n")
+		return err
+	}
+
+	var text bytes.Buffer
+	if err := writeView(&text, FormatText, value, writeTable); err != nil {
+		t.Fatal(err)
+	}
+	if text.String() != "table\n" {
+		t.Errorf("text output = %q, expected the table", text.String())
+	}
+
+	var out bytes.Buffer
+	if err := writeView(&out, FormatJSON, value, writeTable); err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]int
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("JSON output %q does not decode: %v", out.String(), err)
+	}
+	if decoded["total"] != 11 {
+		t.Errorf("decoded total = %d, expected 11", decoded["total"])
+	}
+	if strings.Contains(out.String(), "table") {
+		t.Error("JSON output should not contain the table")
+	}
+
+	if err := writeView(io.Discard, FormatHTML, value, writeTable); err == nil {
+		t.Error("writeView should reject html output")
+	}
+}
+
+func TestMatrixDay01Lists(t *testing.T) {
+	dir := t.TempDir()
+	var files []string
+	for i, content := range []string{"1\n2\n3\n", "3\n2\n1\n", "1   4\n2   5\n3   6\n"} {
+		name := filepath.Join(dir, fmt.Sprintf("list%d.txt", i))
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, name)
+	}
+
+	var out bytes.Buffer
+	opts := ViewOptions{Format: FormatJSON, Lists: splitLists(strings.Join(files, ", "))}
+	if err := matrixDay01(&out, "internal/day01/puzzle-input.txt", opts); err != nil {
+		t.Fatalf("matrixDay01 failed: %v", err)
+	}
+	var matrices day01.Matrices
+	if err := json.Unmarshal(out.Bytes(), &matrices); err != nil {
+		t.Fatalf("JSON output %q does not decode: %v", out.String(), err)
+	}
+
+	// Two single-column files and one two-column file give four lists
+	if len(matrices.Distance) != 4 || matrices.Distance[0][1] != 0 || matrices.Distance[0][3] != 9 {
+		t.Errorf("matrixDay01() distances = %v, expected 4 lists with 0 from list 1 to 2 and 9 to 4", matrices.Distance)
+	}
+}
+
+func TestDiagnoseDay02(t *testing.T) {
+	policy := day02.PuzzlePolicy()
+	policy.DampenerBudget = -1
+
+	var out bytes.Buffer
+	opts := ViewOptions{Format: FormatJSON, Day02Policy: &policy}
+	if err := diagnoseDay02(&out, "internal/day02/example-input.txt", opts); err != nil {
+		t.Fatalf("diagnoseDay02 failed: %v", err)
+	}
+
+	var summary day02.DiagnosisSummary
+	if err := json.Unmarshal(out.Bytes(), &summary); err != nil {
+		t.Fatalf("JSON output %q does not decode: %v", out.String(), err)
+	}
+	if summary.Reports != 6 || summary.Safe != 2 || len(summary.Unrepairable) != 2 {
+		t.Errorf("diagnoseDay02() = %+v, expected 6 reports, 2 safe, 2 unrepairable", summary)
+	}
+}
+
+func TestAnnotateDay03(t *testing.T) {
+	var out bytes.Buffer
+	opts := ViewOptions{Format: FormatHTML}
+	if err := annotateDay03(&out, "internal/day03/example-part2-input.txt", opts); err != nil {
+		t.Fatalf("annotateDay03 failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "Part 2: 2 mul instructions counted, total 48") {
+		t.Errorf("annotateDay03() should default to part 2:\n%s", out.String())
+	}
+
+	out.Reset()
+	opts = ViewOptions{Part: 1, Format: FormatJSON}
+	if err := annotateDay03(&out, "internal/day03/example-part2-input.txt", opts); err != nil {
+		t.Fatalf("annotateDay03 failed: %v", err)
+	}
+	var program day03.AnnotatedProgram
+	if err := json.Unmarshal(out.Bytes(), &program); err != nil {
+		t.Fatalf("JSON output %q does not decode: %v", out.String(), err)
+	}
+	if program.Part != 1 || program.Total != 161 || len(program.Instructions) != 4 {
+		t.Errorf("annotateDay03() = %+v, expected part 1 with 4 instructions totalling 161", program)
+	}
+}
+
+func TestAuditDay03(t *testing.T) {
+	var out bytes.Buffer
+	grammar := day03.Grammar{AllowSigns: true}
+	opts := ViewOptions{Part: 1, Format: FormatJSON, Day03Grammar: &grammar}
+	if err := auditDay03(&out, "internal/day03/example-input.txt", opts); err != nil {
+		t.Fatalf("auditDay03 failed: %v", err)
+	}
+
+	var audit day03.Audit
+	if err := json.Unmarshal(out.Bytes(), &audit); err != nil {
+		t.Fatalf("JSON output %q does not decode: %v", out.String(), err)
+	}
+	// The example's only near miss is mul(32,64]
+	if audit.Part != 1 || audit.Accepted != 4 || len(audit.NearMisses) != 1 {
+		t.Errorf("auditDay03() = %+v, expected part 1 with 4 accepted and 1 near miss", audit)
+	}
+}
+
+func TestWordSearchDay04(t *testing.T) {
+	wordList := t.TempDir() + "/words.txt"
+	if err := os.WriteFile(wordList, []byte("XMAS\nSAMX\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	inputFile := "internal/day04/example-input.txt"
+
+	var out bytes.Buffer
+	if err := wordsDay04(&out, inputFile, ViewOptions{Format: FormatJSON, Words: wordList}); err != nil {
+		t.Fatalf("wordsDay04 failed: %v", err)
+	}
+	var counts []day04.WordCount
+	if err := json.Unmarshal(out.Bytes(), &counts); err != nil {
+		t.Fatalf("JSON output %q does not decode: %v", out.String(), err)
+	}
+	// SAMX is XMAS read backwards, so the two are found equally often
+	if len(counts) != 2 || counts[0].Count != 18 || counts[1].Count != 18 {
+		t.Errorf("wordsDay04() = %+v, expected 18 of each word", counts)
+	}
+
+	out.Reset()
+	if err := matchesDay04(&out, inputFile, ViewOptions{Format: FormatText}); err != nil {
+		t.Fatalf("matchesDay04 failed: %v", err)
+	}
+	if !strings.HasSuffix(out.String(), "18 matches\n") {
+		t.Errorf("matchesDay04() without a word list should find the 18 XMAS:\n%s", out.String())
+	}
+}
+
+func TestVisualizeDay06RejectsOtherFormats(t *testing.T) {
+	for _, format := range []string{FormatJSON, FormatHTML} {
+		var out bytes.Buffer
+		if err := visualizeDay06(&out, "internal/day06/example-input.txt", ViewOptions{Format: format}); err == nil {
+			t.Errorf("visualizeDay06() with -format %s expected error", format)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := visualizeDay06(&out, "internal/day06/example-input.txt", ViewOptions{Format: FormatText}); err != nil {
+		t.Errorf("visualizeDay06() with -format %s error = %v", FormatText, err)
+	}
+}
+
+func TestViolationsDay05(t *testing.T) {
+	inputFile := "internal/day05/example-input.txt"
+
+	var out bytes.Buffer
+	if err := violationsDay05(&out, inputFile, ViewOptions{Format: FormatJSON, Top: 3}); err != nil {
+		t.Fatalf("violationsDay05 failed: %v", err)
+	}
+	var summary day05.ViolationSummary
+	if err := json.Unmarshal(out.Bytes(), &summary); err != nil {
+		t.Fatalf("JSON output %q does not decode: %v", out.String(), err)
+	}
+	if summary.Updates != 6 || summary.Invalid != 3 || len(summary.TopRules) != 3 || len(summary.Checks) != 3 {
+		t.Errorf("violationsDay05() = %+v, expected 3 of 6 updates invalid and the top 3 rules", summary)
+	}
+
+	out.Reset()
+	if err := violationsDay05(&out, inputFile, ViewOptions{Format: FormatText, Top: 3}); err != nil {
+		t.Fatalf("violationsDay05 failed: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "6 updates: 3 valid, 3 invalid") {
+		t.Errorf("violationsDay05() text output:\n%s", out.String())
+	}
+}
+
+func TestRulesDay05(t *testing.T) {
+	var out bytes.Buffer
+	if err := rulesDay05(&out, "internal/day05/example-input.txt", ViewOptions{Format: FormatJSON, Top: 5}); err != nil {
+		t.Fatalf("rulesDay05 failed: %v", err)
+	}
+	var analysis day05.RuleAnalysis
+	if err := json.Unmarshal(out.Bytes(), &analysis); err != nil {
+		t.Fatalf("JSON output %q does not decode: %v", out.String(), err)
+	}
+	if analysis.RedundantCount != 15 || len(analysis.Redundant) != 5 || len(analysis.TotalOrder) != 7 {
+		t.Errorf("rulesDay05() = %+v, expected 5 of 15 redundant rules and a total order", analysis)
+	}
+}
+
+func TestRepairsDay05(t *testing.T) {
+	var out bytes.Buffer
+	if err := repairsDay05(&out, "internal/day05/example-input.txt", ViewOptions{Format: FormatText}); err != nil {
+		t.Fatalf("repairsDay05 failed: %v", err)
+	}
+	for _, want := range []string{"update 4 [75 97 47 61 53]: 1 moves to [97 75 47 61 53]", "Part 2 with reorder  123", "Part 2 with minimal  123"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("repairsDay05() output missing %q:\n%s", want, out.String())
+		}
+	}
+}